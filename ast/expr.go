@@ -18,9 +18,12 @@ type StringExpr struct {
 	String lexer.Token
 }
 
+// AssignmentExpr is an assignment. Operator is either = or an assignment
+// operation such as +=.
 type AssignmentExpr struct {
-	Left  Expr
-	Right Expr
+	Left     Expr
+	Operator lexer.Token
+	Right    Expr
 }
 
 type DeclAssignExpr struct {
@@ -40,22 +43,113 @@ type AccessExpr struct {
 }
 
 type FuncCallExpr struct {
-	Func    Expr
-	Args    Expr
+	Func        Expr
+	Args        Expr
+	ParenOpen   lexer.Token
+	Ellipsis    bool
+	OrPanic     bool
+	OrReturn    bool
+	OrWrap      Expr
+	OrElse      Expr
+	OrElseErr   lexer.Token
+	OrElseBlock Stmt
+	Catches     []CatchClause
+}
+
+// CompositeLitExpr is a composite literal. Type is nil if it is elided in an
+// enclosing literal. MultiLine is set if the elements start on a new line.
+type CompositeLitExpr struct {
+	Type      Expr
+	BraceOpen lexer.Token
+	Elements  []CompositeElement
+	MultiLine bool
+}
+
+// CompositeElement is an element of a composite literal. Key is nil unless
+// the element is keyed.
+type CompositeElement struct {
+	Key   Expr
+	Value Expr
+}
+
+type CatchClause struct {
+	Value Expr
+	Type  Expr
+	Name  lexer.Token
+	Block Stmt
+}
+
+type UnaryExpr struct {
+	Operator lexer.Token
+	Value    Expr
+}
+
+type IndexExpr struct {
+	Value       Expr
+	Index       Expr
+	BracketOpen lexer.Token
+	OrPanic     bool
+}
+
+// SliceExpr is a slice expression. Low, High and Max are nil if they are
+// omitted. Slice3 is set for a full slice expression with a Max.
+type SliceExpr struct {
+	Value       Expr
+	BracketOpen lexer.Token
+	Low         Expr
+	High        Expr
+	Max         Expr
+	Slice3      bool
+}
+
+// TypeAssertExpr is a type assertion. Type is nil for the x.(type) guard of
+// a type switch.
+type TypeAssertExpr struct {
+	Value     Expr
+	Type      Expr
+	ParenOpen lexer.Token
+	OrPanic   bool
+}
+
+type ReceiveExpr struct {
+	Channel Expr
+	Arrow   lexer.Token
 	OrPanic bool
 }
 
+// FuncLitExpr is a function literal.
+type FuncLitExpr struct {
+	Type  FuncType
+	Block Stmt
+}
+
+// SendExpr sends Value on Channel. Like an assignment, it is only valid as a
+// statement.
+type SendExpr struct {
+	Channel Expr
+	Arrow   lexer.Token
+	Value   Expr
+}
+
 type ListExpr struct {
 	Value Expr
 	Next  Expr
 }
 
-func (SymbolExpr) _NOP_expr()     {}
-func (NumberExpr) _NOP_expr()     {}
-func (StringExpr) _NOP_expr()     {}
-func (AssignmentExpr) _NOP_expr() {}
-func (DeclAssignExpr) _NOP_expr() {}
-func (BinaryExpr) _NOP_expr()     {}
-func (AccessExpr) _NOP_expr()     {}
-func (FuncCallExpr) _NOP_expr()   {}
-func (ListExpr) _NOP_expr()       {}
+func (SymbolExpr) _NOP_expr()       {}
+func (NumberExpr) _NOP_expr()       {}
+func (StringExpr) _NOP_expr()       {}
+func (AssignmentExpr) _NOP_expr()   {}
+func (DeclAssignExpr) _NOP_expr()   {}
+func (BinaryExpr) _NOP_expr()       {}
+func (UnaryExpr) _NOP_expr()        {}
+func (AccessExpr) _NOP_expr()       {}
+func (FuncCallExpr) _NOP_expr()     {}
+func (IndexExpr) _NOP_expr()        {}
+func (SliceExpr) _NOP_expr()        {}
+func (TypeAssertExpr) _NOP_expr()   {}
+func (ReceiveExpr) _NOP_expr()      {}
+func (FuncLitExpr) _NOP_expr()      {}
+func (SendExpr) _NOP_expr()         {}
+func (ListExpr) _NOP_expr()         {}
+func (CompositeLitExpr) _NOP_expr() {}
@@ -18,35 +18,157 @@ type ImportStmt struct {
 	PackageNames []lexer.Token
 }
 
+type DirectiveStmt struct {
+	Directive lexer.Token
+}
+
+// FuncParameter is a group of parameters or results of a function sharing a
+// type, such as a, b int. Names is empty for unnamed parameters and results.
+// Variadic is set for the final ...T parameter.
 type FuncParameter struct {
-	Name lexer.Token
-	Type lexer.Token
+	Names    []lexer.Token
+	Type     TypeExpr
+	Variadic bool
 }
 
+// FuncDeclStmt is a function declaration, or a method declaration if
+// Receiver is not nil.
 type FuncDeclStmt struct {
-	Name        lexer.Token
-	Parameters  []FuncParameter
-	ReturnTypes []lexer.Token
-	Block       Stmt
+	Receiver   *FuncParameter
+	Name       lexer.Token
+	TypeParams []TypeParam
+	Parameters []FuncParameter
+	Results    []FuncParameter
+	Block      Stmt
+}
+
+type TypeDeclStmt struct {
+	Name       lexer.Token
+	TypeParams []TypeParam
+	Type       TypeExpr
 }
 
 type VarDeclStmt struct {
 	Name lexer.Token
-	Type lexer.Token
+	Type TypeExpr
 }
 
 type ReturnStmt struct {
 	Values Expr
 }
 
+type IfStmt struct {
+	If    lexer.Token
+	Init  Stmt
+	Cond  Expr
+	Block Stmt
+	// Else is either an IfStmt or a BlockStmt.
+	Else Stmt
+}
+
+// ForStmt is a three-clause, condition-only or infinite for loop. Label is
+// empty unless the loop is labeled.
+type ForStmt struct {
+	For   lexer.Token
+	Label lexer.Token
+	Init  Stmt
+	Cond  Expr
+	Post  Stmt
+	Block Stmt
+}
+
+// RangeStmt is a for loop with a range clause. Key and Value are nil if
+// they are omitted.
+type RangeStmt struct {
+	For     lexer.Token
+	Label   lexer.Token
+	Key     Expr
+	Value   Expr
+	Declare bool
+	Range   Expr
+	Block   Stmt
+}
+
+// SwitchStmt is an expression switch. Tag is nil for a tagless switch.
+type SwitchStmt struct {
+	Switch lexer.Token
+	Label  lexer.Token
+	Init   Stmt
+	Tag    Expr
+	Cases  []CaseClause
+}
+
+// SelectStmt is a select statement. The Values of each case is the send or
+// the receive of the case, which may assign or declare the received values.
+type SelectStmt struct {
+	Select lexer.Token
+	Label  lexer.Token
+	Cases  []CaseClause
+}
+
+// CaseClause is a case of a switch. Values is nil for the default case.
+type CaseClause struct {
+	Case   lexer.Token
+	Values Expr
+	Body   []Stmt
+}
+
+// TypeSwitchStmt is a type switch on Value. Binding is empty unless the
+// switch binds the value to a name in each clause. The values of the cases
+// are types.
+type TypeSwitchStmt struct {
+	Switch  lexer.Token
+	Label   lexer.Token
+	Init    Stmt
+	Binding lexer.Token
+	Value   Expr
+	Cases   []CaseClause
+}
+
+// BranchStmt is a break, continue or fallthrough with an optional label.
+type BranchStmt struct {
+	Keyword lexer.Token
+	Label   lexer.Token
+}
+
+// DeferStmt defers Call, which is a function call, until the surrounding
+// function returns.
+type DeferStmt struct {
+	Defer lexer.Token
+	Call  Expr
+}
+
+// GoStmt runs Call, which is a function call, in a new goroutine.
+type GoStmt struct {
+	Go   lexer.Token
+	Call Expr
+}
+
+type IncDecStmt struct {
+	Value    Expr
+	Operator lexer.Token
+}
+
 type ExprStmt struct {
 	Expr Expr
 }
 
-func (BlockStmt) _NOP_stmt()    {}
-func (PackageStmt) _NOP_stmt()  {}
-func (ImportStmt) _NOP_stmt()   {}
-func (FuncDeclStmt) _NOP_stmt() {}
-func (VarDeclStmt) _NOP_stmt()  {}
-func (ReturnStmt) _NOP_stmt()   {}
-func (ExprStmt) _NOP_stmt()     {}
+func (BlockStmt) _NOP_stmt()      {}
+func (PackageStmt) _NOP_stmt()    {}
+func (ImportStmt) _NOP_stmt()     {}
+func (DirectiveStmt) _NOP_stmt()  {}
+func (FuncDeclStmt) _NOP_stmt()   {}
+func (TypeDeclStmt) _NOP_stmt()   {}
+func (VarDeclStmt) _NOP_stmt()    {}
+func (ReturnStmt) _NOP_stmt()     {}
+func (IfStmt) _NOP_stmt()         {}
+func (ForStmt) _NOP_stmt()        {}
+func (RangeStmt) _NOP_stmt()      {}
+func (SwitchStmt) _NOP_stmt()     {}
+func (TypeSwitchStmt) _NOP_stmt() {}
+func (BranchStmt) _NOP_stmt()     {}
+func (IncDecStmt) _NOP_stmt()     {}
+func (SelectStmt) _NOP_stmt()     {}
+func (DeferStmt) _NOP_stmt()      {}
+func (GoStmt) _NOP_stmt()         {}
+func (ExprStmt) _NOP_stmt()       {}
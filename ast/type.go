@@ -0,0 +1,124 @@
+package ast
+
+import "github.com/tobiashort/gox/lexer"
+
+// TypeExpr is a type. Types are expressions as well, since they are operands
+// of conversions, composite literals and builtins like make.
+type TypeExpr interface {
+	Expr
+	_NOP_type()
+}
+
+// NamedType is a type name, optionally qualified by a package. TypeArgs are
+// the type arguments of an instantiated generic type.
+type NamedType struct {
+	Package  lexer.Token
+	Name     lexer.Token
+	TypeArgs []TypeExpr
+}
+
+type PointerType struct {
+	Star lexer.Token
+	Elem TypeExpr
+}
+
+type SliceType struct {
+	BracketOpen lexer.Token
+	Elem        TypeExpr
+}
+
+type ArrayType struct {
+	BracketOpen lexer.Token
+	Len         Expr
+	Ellipsis    bool
+	Elem        TypeExpr
+}
+
+type MapType struct {
+	Map   lexer.Token
+	Key   TypeExpr
+	Value TypeExpr
+}
+
+type StructType struct {
+	Struct lexer.Token
+	Fields []StructField
+}
+
+// StructField is a field of a struct. Names is empty for an embedded field.
+// Tag is empty unless the field has a tag.
+type StructField struct {
+	Names []lexer.Token
+	Type  TypeExpr
+	Tag   lexer.Token
+}
+
+// ChanDir is the direction of a channel type.
+type ChanDir int
+
+const (
+	ChanBoth ChanDir = iota
+	ChanSend
+	ChanRecv
+)
+
+type ChanType struct {
+	Chan  lexer.Token
+	Dir   ChanDir
+	Value TypeExpr
+}
+
+type FuncType struct {
+	Func       lexer.Token
+	Parameters []FuncParameter
+	Results    []FuncParameter
+}
+
+// InterfaceType is an interface type with methods, embedded interfaces and
+// unions of type terms.
+type InterfaceType struct {
+	Interface lexer.Token
+	Elems     []InterfaceElem
+}
+
+// InterfaceElem is an element of an interface. It is a method if Name is
+// set, otherwise an embedded interface or a union of type terms.
+type InterfaceElem struct {
+	Name       lexer.Token
+	Parameters []FuncParameter
+	Results    []FuncParameter
+	Terms      []TypeTerm
+}
+
+// TypeTerm is a term of a union. Tilde is set for ~T.
+type TypeTerm struct {
+	Tilde bool
+	Type  TypeExpr
+}
+
+// TypeParam is a group of type parameters sharing a constraint, such as
+// K, V comparable.
+type TypeParam struct {
+	Names      []lexer.Token
+	Constraint []TypeTerm
+}
+
+func (NamedType) _NOP_expr()     {}
+func (PointerType) _NOP_expr()   {}
+func (SliceType) _NOP_expr()     {}
+func (ArrayType) _NOP_expr()     {}
+func (MapType) _NOP_expr()       {}
+func (ChanType) _NOP_expr()      {}
+func (FuncType) _NOP_expr()      {}
+func (StructType) _NOP_expr()    {}
+func (InterfaceType) _NOP_expr() {}
+
+func (NamedType) _NOP_type()     {}
+func (PointerType) _NOP_type()   {}
+func (SliceType) _NOP_type()     {}
+func (ArrayType) _NOP_type()     {}
+func (MapType) _NOP_type()       {}
+func (ChanType) _NOP_type()      {}
+func (FuncType) _NOP_type()      {}
+func (StructType) _NOP_type()    {}
+func (InterfaceType) _NOP_type() {}
@@ -44,6 +44,22 @@ func StringHandler() PatternHandler {
 	}
 }
 
+func RawStringHandler() PatternHandler {
+	return func(lexer *Lexer, regex *regexp.Regexp) {
+		loc := regex.FindStringIndex(lexer.Remainder())
+		lexer.Add(NewToken(TokenRawString, lexer.Remainder()[loc[0]+1:loc[1]-1], lexer.Line(), lexer.Column()))
+		lexer.Pos += loc[1]
+	}
+}
+
+func DirectiveHandler() PatternHandler {
+	return func(lexer *Lexer, regex *regexp.Regexp) {
+		value := regex.FindString(lexer.Remainder())
+		lexer.Add(NewToken(TokenDirective, value[len("//gox:"):], lexer.Line(), lexer.Column()))
+		lexer.Pos += len(value)
+	}
+}
+
 func NumberHandler() PatternHandler {
 	return func(lexer *Lexer, regex *regexp.Regexp) {
 		value := regex.FindString(lexer.Remainder())
@@ -54,22 +70,62 @@ func NumberHandler() PatternHandler {
 
 var Patterns = []Pattern{
 	{regexp.MustCompile("^\\n+"), DefaultHandler(TokenNewLine)},
-	{regexp.MustCompile("^\\s+"), SkipHandler()},
+	{regexp.MustCompile("^[^\\S\\n]+"), SkipHandler()},
+	{regexp.MustCompile("^//gox:[^\\n]*"), DirectiveHandler()},
+	{regexp.MustCompile("^//[^\\n]*"), SkipHandler()},
 	{regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*"), IdentifierHandler()},
 	{regexp.MustCompile(`^"[^"]*"`), StringHandler()},
+	{regexp.MustCompile("^`[^`]*`"), RawStringHandler()},
 	{regexp.MustCompile("^\\d+(\\.\\d+)?"), NumberHandler()},
 	{regexp.MustCompile("^package"), DefaultHandler(TokenPackage)},
 	{regexp.MustCompile("^import"), DefaultHandler(TokenImport)},
 	{regexp.MustCompile("^case"), DefaultHandler(TokenCase)},
+	{regexp.MustCompile("^<<="), DefaultHandler(TokenShiftLeftAssign)},
+	{regexp.MustCompile("^>>="), DefaultHandler(TokenShiftRightAssign)},
+	{regexp.MustCompile("^&\\^="), DefaultHandler(TokenAndNotAssign)},
+	{regexp.MustCompile("^\\+="), DefaultHandler(TokenPlusAssign)},
+	{regexp.MustCompile("^-="), DefaultHandler(TokenMinusAssign)},
+	{regexp.MustCompile("^\\*="), DefaultHandler(TokenStarAssign)},
+	{regexp.MustCompile("^/="), DefaultHandler(TokenSlashAssign)},
+	{regexp.MustCompile("^%="), DefaultHandler(TokenPercentAssign)},
+	{regexp.MustCompile("^&="), DefaultHandler(TokenAmpersandAssign)},
+	{regexp.MustCompile("^\\|="), DefaultHandler(TokenPipeAssign)},
+	{regexp.MustCompile("^\\^="), DefaultHandler(TokenCaretAssign)},
+	{regexp.MustCompile("^=="), DefaultHandler(TokenEqual)},
+	{regexp.MustCompile("^!="), DefaultHandler(TokenNotEqual)},
+	{regexp.MustCompile("^<="), DefaultHandler(TokenLessEq)},
+	{regexp.MustCompile("^>="), DefaultHandler(TokenGreaterEq)},
 	{regexp.MustCompile("^="), DefaultHandler(TokenAssign)},
 	{regexp.MustCompile("^:="), DefaultHandler(TokenDeclAssign)},
+	{regexp.MustCompile("^&&"), DefaultHandler(TokenAnd)},
+	{regexp.MustCompile("^\\|\\|"), DefaultHandler(TokenOr)},
+	{regexp.MustCompile("^<<"), DefaultHandler(TokenShiftLeft)},
+	{regexp.MustCompile("^>>"), DefaultHandler(TokenShiftRight)},
+	{regexp.MustCompile("^&\\^"), DefaultHandler(TokenAndNot)},
+	{regexp.MustCompile("^\\+\\+"), DefaultHandler(TokenIncrement)},
+	{regexp.MustCompile("^--"), DefaultHandler(TokenDecrement)},
 	{regexp.MustCompile("^\\+"), DefaultHandler(TokenPlus)},
+	{regexp.MustCompile("^-"), DefaultHandler(TokenMinus)},
 	{regexp.MustCompile("^\\*"), DefaultHandler(TokenStar)},
+	{regexp.MustCompile("^/"), DefaultHandler(TokenSlash)},
+	{regexp.MustCompile("^%"), DefaultHandler(TokenPercent)},
+	{regexp.MustCompile("^&"), DefaultHandler(TokenAmpersand)},
+	{regexp.MustCompile("^\\^"), DefaultHandler(TokenCaret)},
+	{regexp.MustCompile("^!"), DefaultHandler(TokenNot)},
+	{regexp.MustCompile("^<-"), DefaultHandler(TokenArrow)},
+	{regexp.MustCompile("^<"), DefaultHandler(TokenLess)},
+	{regexp.MustCompile("^>"), DefaultHandler(TokenGreater)},
 	{regexp.MustCompile("^\\("), DefaultHandler(TokenParenOpen)},
 	{regexp.MustCompile("^\\)"), DefaultHandler(TokenParenClose)},
 	{regexp.MustCompile("^\\{"), DefaultHandler(TokenBraceOpen)},
 	{regexp.MustCompile("^\\}"), DefaultHandler(TokenBraceClose)},
+	{regexp.MustCompile("^\\["), DefaultHandler(TokenBracketOpen)},
+	{regexp.MustCompile("^\\]"), DefaultHandler(TokenBracketClose)},
+	{regexp.MustCompile("^~"), DefaultHandler(TokenTilde)},
+	{regexp.MustCompile("^\\|"), DefaultHandler(TokenPipe)},
+	{regexp.MustCompile("^\\.\\.\\."), DefaultHandler(TokenEllipsis)},
 	{regexp.MustCompile("^\\."), DefaultHandler(TokenDot)},
 	{regexp.MustCompile("^,"), DefaultHandler(TokenComma)},
 	{regexp.MustCompile("^:"), DefaultHandler(TokenColon)},
+	{regexp.MustCompile("^;"), DefaultHandler(TokenSemicolon)},
 }
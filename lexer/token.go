@@ -6,45 +6,127 @@ type TokenType = string
 
 const (
 	TokenString     = "STRING"
+	TokenRawString  = "RAW_STRING"
 	TokenNumber     = "NUMBER"
 	TokenIdentifier = "IDENTIFIER"
+	TokenDirective  = "DIRECTIVE"
 
 	// operators
 	TokenAssign     = "ASSIGN"
 	TokenDeclAssign = "DECL_ASSIGN"
 	TokenPlus       = "PLUS"
+	TokenIncrement  = "INCREMENT"
+	TokenDecrement  = "DECREMENT"
 	TokenStar       = "STAR"
+	TokenArrow      = "ARROW"
+	TokenTilde      = "TILDE"
+	TokenPipe       = "PIPE"
+	TokenEqual      = "EQUAL"
+	TokenNotEqual   = "NOT_EQUAL"
+	TokenLess       = "LESS"
+	TokenLessEq     = "LESS_EQ"
+	TokenGreater    = "GREATER"
+	TokenGreaterEq  = "GREATER_EQ"
+	TokenMinus      = "MINUS"
+	TokenSlash      = "SLASH"
+	TokenPercent    = "PERCENT"
+	TokenAmpersand  = "AMPERSAND"
+	TokenCaret      = "CARET"
+	TokenShiftLeft  = "SHIFT_LEFT"
+	TokenShiftRight = "SHIFT_RIGHT"
+	TokenAndNot     = "AND_NOT"
+	TokenAnd        = "AND"
+	TokenOr         = "OR"
+	TokenNot        = "NOT"
+
+	TokenPlusAssign       = "PLUS_ASSIGN"
+	TokenMinusAssign      = "MINUS_ASSIGN"
+	TokenStarAssign       = "STAR_ASSIGN"
+	TokenSlashAssign      = "SLASH_ASSIGN"
+	TokenPercentAssign    = "PERCENT_ASSIGN"
+	TokenAmpersandAssign  = "AMPERSAND_ASSIGN"
+	TokenPipeAssign       = "PIPE_ASSIGN"
+	TokenCaretAssign      = "CARET_ASSIGN"
+	TokenShiftLeftAssign  = "SHIFT_LEFT_ASSIGN"
+	TokenShiftRightAssign = "SHIFT_RIGHT_ASSIGN"
+	TokenAndNotAssign     = "AND_NOT_ASSIGN"
 
 	//  punctuation
-	TokenDot        = "DOT"
-	TokenParenOpen  = "PAREN_OPEN"
-	TokenParenClose = "PAREN_CLOSE"
-	TokenBraceOpen  = "BRACE_OPEN"
-	TokenBraceClose = "BRACE_CLOSE"
-	TokenNewLine    = "NEW_LINE"
-	TokenComma      = "COMMA"
-	TokenColon      = "COLON"
+	TokenDot          = "DOT"
+	TokenEllipsis     = "ELLIPSIS"
+	TokenParenOpen    = "PAREN_OPEN"
+	TokenParenClose   = "PAREN_CLOSE"
+	TokenBraceOpen    = "BRACE_OPEN"
+	TokenBraceClose   = "BRACE_CLOSE"
+	TokenBracketOpen  = "BRACKET_OPEN"
+	TokenBracketClose = "BRACKET_CLOSE"
+	TokenNewLine      = "NEW_LINE"
+	TokenComma        = "COMMA"
+	TokenColon        = "COLON"
+	TokenSemicolon    = "SEMICOLON"
 
 	// Keywords
-	TokenPackage = "PACKAGE"
-	TokenImport  = "IMPORT"
-	TokenFunc    = "FUNC"
-	TokenVar     = "VAR"
-	TokenOrPanic = "OR_PANIC"
-	TokenReturn  = "RETURN"
-	TokenCase    = "CASE"
+	TokenPackage     = "PACKAGE"
+	TokenImport      = "IMPORT"
+	TokenFunc        = "FUNC"
+	TokenVar         = "VAR"
+	TokenTypeKeyword = "TYPE"
+	TokenStruct      = "STRUCT"
+	TokenMap         = "MAP"
+	TokenChan        = "CHAN"
+	TokenInterface   = "INTERFACE"
+	TokenOrPanic     = "OR_PANIC"
+	TokenOrReturn    = "OR_RETURN"
+	TokenOrWrap      = "OR_WRAP"
+	TokenOrElse      = "OR_ELSE"
+	TokenCatch       = "CATCH"
+	TokenReturn      = "RETURN"
+	TokenIf          = "IF"
+	TokenElse        = "ELSE"
+	TokenFor         = "FOR"
+	TokenRange       = "RANGE"
+	TokenBreak       = "BREAK"
+	TokenContinue    = "CONTINUE"
+	TokenSwitch      = "SWITCH"
+	TokenSelect      = "SELECT"
+	TokenCase        = "CASE"
+	TokenDefault     = "DEFAULT"
+	TokenFallthrough = "FALLTHROUGH"
+	TokenDefer       = "DEFER"
+	TokenGo          = "GO"
 
 	TokenEOF = "EOF"
 )
 
 var Keywords = map[string]TokenType{
-	"package":  TokenPackage,
-	"import":   TokenImport,
-	"func":     TokenFunc,
-	"var":      TokenVar,
-	"or_panic": TokenOrPanic,
-	"return":   TokenReturn,
-	"case":     TokenCase,
+	"package":     TokenPackage,
+	"import":      TokenImport,
+	"func":        TokenFunc,
+	"var":         TokenVar,
+	"type":        TokenTypeKeyword,
+	"struct":      TokenStruct,
+	"map":         TokenMap,
+	"chan":        TokenChan,
+	"interface":   TokenInterface,
+	"or_panic":    TokenOrPanic,
+	"or_return":   TokenOrReturn,
+	"or_wrap":     TokenOrWrap,
+	"or_else":     TokenOrElse,
+	"catch":       TokenCatch,
+	"return":      TokenReturn,
+	"if":          TokenIf,
+	"else":        TokenElse,
+	"for":         TokenFor,
+	"range":       TokenRange,
+	"break":       TokenBreak,
+	"continue":    TokenContinue,
+	"switch":      TokenSwitch,
+	"select":      TokenSelect,
+	"case":        TokenCase,
+	"default":     TokenDefault,
+	"fallthrough": TokenFallthrough,
+	"defer":       TokenDefer,
+	"go":          TokenGo,
 }
 
 func IsKeyword(value string) bool {
@@ -23,8 +23,12 @@ func usage() {
 	fmt.Fprintf(os.Stderr, `Usage:
 	gox tokenize FILE
 	gox parse FILE
-	gox transpile FILE
-	gox run FILE
+	gox transpile [-onerror STRATEGY] FILE
+	gox run [-onerror STRATEGY] FILE
+
+STRATEGY decides what or_panic does on error: panic (default),
+log.Fatal, os.Exit or the name of a handler function called with the error.
+It can be overridden per file with a //gox:onerror STRATEGY directive.
 `)
 }
 
@@ -44,14 +48,28 @@ func parse(file string) []ast.Stmt {
 	return parser.Stmts
 }
 
-func transpile(file string) string {
+func transpile(file string, onError string) string {
 	data, err := os.ReadFile(file)
 	assert.Nil(err)
 	transpiler := transpiler.NewTranspiler()
+	transpiler.OnError = onError
+	transpiler.FileName = filepath.Base(file)
 	transpiler.Transpile(string(data))
 	return transpiler.String()
 }
 
+func transpileFlags(task string) (file string, onError string) {
+	flags := flag.NewFlagSet(task, flag.ExitOnError)
+	flags.Usage = usage
+	flags.StringVar(&onError, "onerror", transpiler.OnErrorPanic, "")
+	flags.Parse(flag.Args()[1:])
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "must provide file")
+		os.Exit(1)
+	}
+	return flags.Arg(0), onError
+}
+
 func main() {
 	flag.Usage = usage
 	flag.Parse()
@@ -81,20 +99,12 @@ func main() {
 		ast := parse(file)
 		litter.Dump(ast)
 	case "transpile":
-		if flag.NArg() != 2 {
-			fmt.Fprintln(os.Stderr, "must provide file")
-			os.Exit(1)
-		}
-		file := flag.Arg(1)
-		source := transpile(file)
+		file, onError := transpileFlags(task)
+		source := transpile(file, onError)
 		fmt.Println(source)
 	case "run":
-		if flag.NArg() != 2 {
-			fmt.Fprintln(os.Stderr, "must provide file")
-			os.Exit(1)
-		}
-		file := flag.Arg(1)
-		source := transpile(file)
+		file, onError := transpileFlags(task)
+		source := transpile(file, onError)
 		tempDir, err := os.MkdirTemp(os.TempDir(), "gox")
 		assert.Nil(err)
 		defer os.RemoveAll(tempDir)
@@ -19,6 +19,17 @@ func ParseExpr(parser *Parser, bindingPower int) ast.Expr {
 	return left
 }
 
+// ParseNestedExpr parses an expression nested in parentheses, brackets or
+// braces, where composite literals are allowed even in the header of a
+// statement.
+func ParseNestedExpr(parser *Parser, bindingPower int) ast.Expr {
+	inHeader := parser.InHeader
+	parser.InHeader = false
+	expr := ParseExpr(parser, bindingPower)
+	parser.InHeader = inHeader
+	return expr
+}
+
 func ParseSymbolExpr(token lexer.Token) ast.Expr {
 	return ast.SymbolExpr{
 		Symbol: token,
@@ -46,8 +57,9 @@ func ParseDeclAssignExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.E
 
 func ParseAssignmentExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
 	return ast.AssignmentExpr{
-		Left:  left,
-		Right: ParseExpr(parser, BindingPower(parser, token)),
+		Left:     left,
+		Operator: token,
+		Right:    ParseExpr(parser, BindingPower(parser, token)),
 	}
 }
 
@@ -59,42 +71,131 @@ func ParseBinaryExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr
 	}
 }
 
+// ParseDotExpr parses a selector or a type assertion. Selectors chain after
+// any operand, such as a[i].b.c().d.
 func ParseDotExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
-	_, leftIsSymbol := left.(ast.SymbolExpr)
+	if parser.Peek().Type == lexer.TokenParenOpen {
+		return ParseTypeAssertExpr(parser, left, token)
+	}
+	return ast.AccessExpr{
+		Instance: left,
+		Field:    ParseSymbolExpr(parser.Expect(lexer.TokenIdentifier)),
+	}
+}
 
-	right := ParseExpr(parser, BindingPower(parser, token))
-	_, rightIsSymbol := right.(ast.SymbolExpr)
+func ParseTypeAssertExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	typeAssertExpr := ast.TypeAssertExpr{}
+	typeAssertExpr.Value = left
+	typeAssertExpr.ParenOpen = parser.Expect(lexer.TokenParenOpen)
+	if parser.Peek().Type == lexer.TokenTypeKeyword {
+		parser.Advance()
+		parser.Expect(lexer.TokenParenClose)
+		return typeAssertExpr
+	}
+	typeAssertExpr.Type = ParseTypeOperand(parser)
+	parser.Expect(lexer.TokenParenClose)
+	return typeAssertExpr
+}
 
-	if leftIsSymbol && rightIsSymbol {
-		return ast.AccessExpr{
-			Instance: left,
-			Field:    right,
+// ParseTypeOperand parses a type used as an operand, such as the type of a
+// type assertion, which may be a pointer type.
+func ParseTypeOperand(parser *Parser) ast.Expr {
+	if parser.Peek().Type == lexer.TokenStar {
+		return ast.UnaryExpr{
+			Operator: parser.Advance(),
+			Value:    ParseExpr(parser, 13),
 		}
 	}
+	return ParseExpr(parser, 13)
+}
 
-	parser.InvalidToken(token)
-	return nil
+// ParseBracketOpenExpr parses an index or a slice expression. Indexing and
+// the instantiation of a generic function or type, such as Map[int, string],
+// cannot be told apart without knowing what the operand is, so both are
+// parsed as an index expression. The type arguments of an instantiation are
+// a list.
+func ParseBracketOpenExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	var index ast.Expr
+	if parser.Peek().Type != lexer.TokenColon {
+		index = ParseNestedExpr(parser, 0)
+	}
+	if parser.Peek().Type == lexer.TokenColon {
+		return ParseSliceExpr(parser, left, token, index)
+	}
+	indexExpr := ast.IndexExpr{}
+	indexExpr.Value = left
+	indexExpr.BracketOpen = token
+	indexExpr.Index = index
+	parser.Expect(lexer.TokenBracketClose)
+	return indexExpr
+}
+
+// ParseSliceExpr parses the rest of a slice expression after its low index,
+// which is nil if it is omitted.
+func ParseSliceExpr(parser *Parser, left ast.Expr, token lexer.Token, low ast.Expr) ast.Expr {
+	sliceExpr := ast.SliceExpr{}
+	sliceExpr.Value = left
+	sliceExpr.BracketOpen = token
+	sliceExpr.Low = low
+	parser.Expect(lexer.TokenColon)
+	switch parser.Peek().Type {
+	case lexer.TokenColon, lexer.TokenBracketClose:
+	default:
+		sliceExpr.High = ParseNestedExpr(parser, 0)
+	}
+	if parser.Peek().Type == lexer.TokenColon {
+		parser.Advance()
+		sliceExpr.Slice3 = true
+		// the high and the max index of a full slice expression are required
+		if sliceExpr.High == nil {
+			parser.InvalidToken(token)
+		}
+		sliceExpr.Max = ParseNestedExpr(parser, 0)
+	}
+	parser.Expect(lexer.TokenBracketClose)
+	return sliceExpr
+}
+
+// ParseUnaryExpr parses a unary expression. The operand binds the checks
+// that follow it, so -f() or_panic negates the checked result of f.
+func ParseUnaryExpr(parser *Parser, token lexer.Token) ast.Expr {
+	return ast.UnaryExpr{
+		Operator: token,
+		Value:    ParseExpr(parser, 12),
+	}
+}
+
+func ParseReceiveExpr(parser *Parser, token lexer.Token) ast.Expr {
+	return ast.ReceiveExpr{
+		Channel: ParseExpr(parser, 13),
+		Arrow:   token,
+	}
 }
 
 func ParseParenOpenExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
 	if left == nil {
-		expr := ParseExpr(parser, 1)
+		expr := ParseNestedExpr(parser, 1)
 		parser.Expect(lexer.TokenParenClose)
 		return expr
 	}
 
-	_, isSymbolExpr := left.(ast.SymbolExpr)
-	_, isAccessExpr := left.(ast.AccessExpr)
-
-	if isSymbolExpr || isAccessExpr {
+	// an index expression may be an instantiation such as Map[int, string]
+	switch left.(type) {
+	case ast.SymbolExpr, ast.AccessExpr, ast.IndexExpr, ast.FuncCallExpr, ast.TypeAssertExpr, ast.FuncLitExpr, ast.TypeExpr,
+		ast.UnaryExpr, ast.ReceiveExpr:
 		funcCallExpr := ast.FuncCallExpr{}
 		funcCallExpr.Func = left
+		funcCallExpr.ParenOpen = token
 		if parser.Peek().Type == lexer.TokenParenClose {
 			parser.Advance()
 			return funcCallExpr
 		}
-		args := ParseExpr(parser, BindingPower(parser, token))
+		args := ParseNestedExpr(parser, 0)
 		funcCallExpr.Args = args
+		if parser.Peek().Type == lexer.TokenEllipsis {
+			parser.Advance()
+			funcCallExpr.Ellipsis = true
+		}
 		parser.Expect(lexer.TokenParenClose)
 		return funcCallExpr
 	}
@@ -103,6 +204,83 @@ func ParseParenOpenExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Ex
 	return nil
 }
 
+// ParseFuncLitExpr parses a function literal, or a function type if no body
+// follows.
+func ParseFuncLitExpr(parser *Parser, token lexer.Token) ast.Expr {
+	funcType := ParseTypeExprAt(parser, token).(ast.FuncType)
+	if parser.Peek().Type != lexer.TokenBraceOpen {
+		return funcType
+	}
+	return ast.FuncLitExpr{
+		Type:  funcType,
+		Block: ParseBlockStmt(parser),
+	}
+}
+
+// ParseTypeLitExpr parses a type that starts with a token other than a type
+// name, such as a slice or map type, and the composite literal of that type
+// if a brace follows.
+func ParseTypeLitExpr(parser *Parser, token lexer.Token) ast.Expr {
+	_type := ParseTypeExprAt(parser, token)
+	if parser.Peek().Type == lexer.TokenBraceOpen {
+		return ParseCompositeLitExpr(parser, _type, parser.Advance())
+	}
+	return _type
+}
+
+func ParseCompositeLitExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	switch left.(type) {
+	case nil, ast.SymbolExpr, ast.AccessExpr, ast.IndexExpr, ast.TypeExpr:
+	default:
+		parser.InvalidToken(token)
+	}
+	compositeLitExpr := ast.CompositeLitExpr{}
+	compositeLitExpr.Type = left
+	compositeLitExpr.BraceOpen = token
+	compositeLitExpr.MultiLine = parser.Peek().Type == lexer.TokenNewLine
+	compositeLitExpr.Elements = make([]ast.CompositeElement, 0)
+	for {
+		for parser.Peek().Type == lexer.TokenNewLine {
+			parser.Advance()
+		}
+		if parser.Peek().Type == lexer.TokenBraceClose {
+			parser.Advance()
+			return compositeLitExpr
+		}
+		element := ast.CompositeElement{}
+		element.Value = ParseElementValue(parser)
+		if parser.Peek().Type == lexer.TokenColon {
+			parser.Advance()
+			element.Key = element.Value
+			element.Value = ParseElementValue(parser)
+		}
+		compositeLitExpr.Elements = append(compositeLitExpr.Elements, element)
+		for parser.Peek().Type == lexer.TokenNewLine {
+			parser.Advance()
+		}
+		if parser.Peek().Type != lexer.TokenBraceClose {
+			parser.Expect(lexer.TokenComma)
+		}
+	}
+}
+
+// ParseElementValue parses a key or a value of a composite literal, which is
+// a composite literal with an elided type if it starts with a brace.
+func ParseElementValue(parser *Parser) ast.Expr {
+	if parser.Peek().Type == lexer.TokenBraceOpen {
+		return ParseCompositeLitExpr(parser, nil, parser.Advance())
+	}
+	return ParseNestedExpr(parser, 2)
+}
+
+func ParseSendExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	return ast.SendExpr{
+		Channel: left,
+		Arrow:   token,
+		Value:   ParseExpr(parser, BindingPower(parser, token)),
+	}
+}
+
 func ParseListExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
 	return ast.ListExpr{
 		Value: left,
@@ -111,8 +289,145 @@ func ParseListExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
 }
 
 func ParseOrPanicExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
-	funcCallExpr := left.(ast.FuncCallExpr)
-	funcCallExpr.OrPanic = true
+	switch expr := left.(type) {
+	case ast.FuncCallExpr:
+		expr.OrPanic = true
+		return expr
+	case ast.IndexExpr:
+		expr.OrPanic = true
+		return expr
+	case ast.TypeAssertExpr:
+		expr.OrPanic = true
+		return expr
+	case ast.ReceiveExpr:
+		expr.OrPanic = true
+		return expr
+	default:
+		parser.InvalidToken(token)
+		return nil
+	}
+}
+
+func ParseOrReturnExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	funcCallExpr, isFuncCallExpr := left.(ast.FuncCallExpr)
+	if !isFuncCallExpr {
+		parser.InvalidToken(token)
+	}
+	funcCallExpr.OrReturn = true
+	return funcCallExpr
+}
+
+func ParseOrWrapExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	funcCallExpr, isFuncCallExpr := left.(ast.FuncCallExpr)
+	if !isFuncCallExpr {
+		parser.InvalidToken(token)
+	}
+	funcCallExpr.OrWrap = ParseStringExpr(parser.Expect(lexer.TokenString))
+	return funcCallExpr
+}
+
+func ParseOrElseExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	funcCallExpr, isFuncCallExpr := left.(ast.FuncCallExpr)
+	if !isFuncCallExpr {
+		parser.InvalidToken(token)
+	}
+	if parser.Peek().Type == lexer.TokenIdentifier && parser.PeekAhead(1).Type == lexer.TokenBraceOpen &&
+		!IsCompositeLitAhead(parser, 1) {
+		funcCallExpr.OrElseErr = parser.Advance()
+	}
+	if parser.Peek().Type == lexer.TokenBraceOpen {
+		funcCallExpr.OrElseBlock = ParseBlockStmt(parser)
+	} else {
+		funcCallExpr.OrElse = ParseExpr(parser, 2)
+	}
+	return funcCallExpr
+}
+
+// IsCompositeLitAhead reports whether the brace at the given offset opens the
+// elements of a composite literal rather than a block, as in
+// `or_else Point{X: 7}` versus `or_else err { ... }`. It is a composite
+// literal if the first element is a literal, is keyed or is followed by a
+// comma outside of an assignment, which cannot start a statement. Otherwise,
+// like in the header of an if statement, it is a block, and a literal such
+// as Point{} must be written in parentheses.
+func IsCompositeLitAhead(parser *Parser, offset int) bool {
+	n := offset + 1
+	for parser.PeekAhead(n).Type == lexer.TokenNewLine {
+		n++
+	}
+	switch parser.PeekAhead(n).Type {
+	case lexer.TokenNumber, lexer.TokenString, lexer.TokenRawString:
+		return true
+	case lexer.TokenIdentifier:
+	default:
+		return false
+	}
+	if parser.PeekAhead(n+1).Type == lexer.TokenColon {
+		n += 2
+		for parser.PeekAhead(n).Type == lexer.TokenNewLine {
+			n++
+		}
+		switch parser.PeekAhead(n).Type {
+		case lexer.TokenFor, lexer.TokenSwitch, lexer.TokenSelect:
+			// a labeled statement
+			return false
+		default:
+			return true
+		}
+	}
+	depth := 0
+	comma := false
+	for ; ; n++ {
+		switch parser.PeekAhead(n).Type {
+		case lexer.TokenParenOpen, lexer.TokenBracketOpen, lexer.TokenBraceOpen:
+			depth++
+		case lexer.TokenParenClose, lexer.TokenBracketClose:
+			depth--
+		case lexer.TokenBraceClose:
+			if depth == 0 {
+				return comma
+			}
+			depth--
+		case lexer.TokenComma:
+			comma = comma || depth == 0
+		case lexer.TokenAssign, lexer.TokenDeclAssign:
+			if depth == 0 {
+				return false
+			}
+		case lexer.TokenNewLine, lexer.TokenSemicolon:
+			if depth == 0 && !comma {
+				return false
+			}
+		case lexer.TokenEOF:
+			return false
+		}
+	}
+}
+
+// ParseCatchExpr parses a catch clause, which is either
+// `catch Value { ... }` or `catch Type as name { ... }`.
+func ParseCatchExpr(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
+	funcCallExpr, isFuncCallExpr := left.(ast.FuncCallExpr)
+	if !isFuncCallExpr {
+		parser.InvalidToken(token)
+	}
+	catchClause := ast.CatchClause{}
+	// the brace after the target starts the block of the clause
+	inHeader := parser.InHeader
+	parser.InHeader = true
+	target := ParseTypeOperand(parser)
+	parser.InHeader = inHeader
+	if parser.Peek().Type == lexer.TokenIdentifier && parser.Peek().Value == "as" {
+		parser.Advance()
+		catchClause.Type = target
+		catchClause.Name = parser.Expect(lexer.TokenIdentifier)
+	} else if _, isUnaryExpr := target.(ast.UnaryExpr); isUnaryExpr {
+		parser.InvalidToken(parser.Peek())
+	} else {
+		catchClause.Value = target
+	}
+	catchClause.Block = ParseBlockStmt(parser)
+	funcCallExpr.Catches = append(funcCallExpr.Catches, catchClause)
 	return funcCallExpr
 }
 
@@ -120,19 +435,92 @@ func BindingPower(parser *Parser, token lexer.Token) int {
 	switch token.Type {
 	case lexer.TokenOrPanic:
 		fallthrough
+	case lexer.TokenOrReturn:
+		fallthrough
+	case lexer.TokenOrWrap:
+		fallthrough
+	case lexer.TokenOrElse:
+		fallthrough
+	case lexer.TokenCatch:
+		return 13
 	case lexer.TokenParenOpen:
 		fallthrough
+	case lexer.TokenBracketOpen:
+		fallthrough
 	case lexer.TokenDot:
 		return 14
 	case lexer.TokenStar:
+		fallthrough
+	case lexer.TokenSlash:
+		fallthrough
+	case lexer.TokenPercent:
+		fallthrough
+	case lexer.TokenShiftLeft:
+		fallthrough
+	case lexer.TokenShiftRight:
+		fallthrough
+	case lexer.TokenAmpersand:
+		fallthrough
+	case lexer.TokenAndNot:
 		return 12
 	case lexer.TokenPlus:
+		fallthrough
+	case lexer.TokenMinus:
+		fallthrough
+	case lexer.TokenPipe:
+		fallthrough
+	case lexer.TokenCaret:
 		return 11
+	case lexer.TokenEqual:
+		fallthrough
+	case lexer.TokenNotEqual:
+		fallthrough
+	case lexer.TokenLess:
+		fallthrough
+	case lexer.TokenLessEq:
+		fallthrough
+	case lexer.TokenGreater:
+		fallthrough
+	case lexer.TokenGreaterEq:
+		return 10
+	case lexer.TokenAnd:
+		return 9
+	case lexer.TokenOr:
+		return 8
+	case lexer.TokenBraceOpen:
+		if parser.InHeader {
+			return 0
+		}
+		return 14
+	case lexer.TokenComma:
+		return 2
 	case lexer.TokenAssign:
 		fallthrough
+	case lexer.TokenPlusAssign:
+		fallthrough
+	case lexer.TokenMinusAssign:
+		fallthrough
+	case lexer.TokenStarAssign:
+		fallthrough
+	case lexer.TokenSlashAssign:
+		fallthrough
+	case lexer.TokenPercentAssign:
+		fallthrough
+	case lexer.TokenAmpersandAssign:
+		fallthrough
+	case lexer.TokenPipeAssign:
+		fallthrough
+	case lexer.TokenCaretAssign:
+		fallthrough
+	case lexer.TokenShiftLeftAssign:
+		fallthrough
+	case lexer.TokenShiftRightAssign:
+		fallthrough
+	case lexer.TokenAndNotAssign:
+		fallthrough
 	case lexer.TokenDeclAssign:
-		return 2
-	case lexer.TokenComma:
+		fallthrough
+	case lexer.TokenArrow:
 		return 1
 	case lexer.TokenNumber:
 		fallthrough
@@ -140,6 +528,20 @@ func BindingPower(parser *Parser, token lexer.Token) int {
 		fallthrough
 	case lexer.TokenParenClose:
 		fallthrough
+	case lexer.TokenBracketClose:
+		fallthrough
+	case lexer.TokenBraceClose:
+		fallthrough
+	case lexer.TokenSemicolon:
+		fallthrough
+	case lexer.TokenColon:
+		fallthrough
+	case lexer.TokenIncrement:
+		fallthrough
+	case lexer.TokenDecrement:
+		fallthrough
+	case lexer.TokenEllipsis:
+		fallthrough
 	case lexer.TokenNewLine:
 		return 0
 	default:
@@ -151,6 +553,8 @@ func BindingPower(parser *Parser, token lexer.Token) int {
 func NUD(parser *Parser, token lexer.Token) ast.Expr {
 	switch token.Type {
 	case lexer.TokenString:
+		fallthrough
+	case lexer.TokenRawString:
 		return ParseStringExpr(token)
 	case lexer.TokenIdentifier:
 		return ParseSymbolExpr(token)
@@ -158,6 +562,32 @@ func NUD(parser *Parser, token lexer.Token) ast.Expr {
 		return ParseNumberExpr(token)
 	case lexer.TokenParenOpen:
 		return ParseParenOpenExpr(parser, nil, token)
+	case lexer.TokenArrow:
+		return ParseReceiveExpr(parser, token)
+	case lexer.TokenMinus:
+		fallthrough
+	case lexer.TokenPlus:
+		fallthrough
+	case lexer.TokenNot:
+		fallthrough
+	case lexer.TokenCaret:
+		fallthrough
+	case lexer.TokenAmpersand:
+		fallthrough
+	case lexer.TokenStar:
+		return ParseUnaryExpr(parser, token)
+	case lexer.TokenFunc:
+		return ParseFuncLitExpr(parser, token)
+	case lexer.TokenBracketOpen:
+		fallthrough
+	case lexer.TokenMap:
+		fallthrough
+	case lexer.TokenChan:
+		fallthrough
+	case lexer.TokenStruct:
+		fallthrough
+	case lexer.TokenInterface:
+		return ParseTypeLitExpr(parser, token)
 	default:
 		parser.InvalidToken(token)
 		return nil
@@ -168,18 +598,88 @@ func LED(parser *Parser, left ast.Expr, token lexer.Token) ast.Expr {
 	switch token.Type {
 	case lexer.TokenOrPanic:
 		return ParseOrPanicExpr(parser, left, token)
+	case lexer.TokenOrReturn:
+		return ParseOrReturnExpr(parser, left, token)
+	case lexer.TokenOrWrap:
+		return ParseOrWrapExpr(parser, left, token)
+	case lexer.TokenOrElse:
+		return ParseOrElseExpr(parser, left, token)
+	case lexer.TokenCatch:
+		return ParseCatchExpr(parser, left, token)
 	case lexer.TokenParenOpen:
 		return ParseParenOpenExpr(parser, left, token)
+	case lexer.TokenBracketOpen:
+		return ParseBracketOpenExpr(parser, left, token)
+	case lexer.TokenBraceOpen:
+		return ParseCompositeLitExpr(parser, left, token)
 	case lexer.TokenDot:
 		return ParseDotExpr(parser, left, token)
 	case lexer.TokenStar:
 		fallthrough
+	case lexer.TokenSlash:
+		fallthrough
+	case lexer.TokenPercent:
+		fallthrough
+	case lexer.TokenShiftLeft:
+		fallthrough
+	case lexer.TokenShiftRight:
+		fallthrough
+	case lexer.TokenAmpersand:
+		fallthrough
+	case lexer.TokenAndNot:
+		fallthrough
 	case lexer.TokenPlus:
+		fallthrough
+	case lexer.TokenMinus:
+		fallthrough
+	case lexer.TokenPipe:
+		fallthrough
+	case lexer.TokenCaret:
+		fallthrough
+	case lexer.TokenEqual:
+		fallthrough
+	case lexer.TokenNotEqual:
+		fallthrough
+	case lexer.TokenLess:
+		fallthrough
+	case lexer.TokenLessEq:
+		fallthrough
+	case lexer.TokenGreater:
+		fallthrough
+	case lexer.TokenGreaterEq:
+		fallthrough
+	case lexer.TokenAnd:
+		fallthrough
+	case lexer.TokenOr:
 		return ParseBinaryExpr(parser, left, token)
 	case lexer.TokenAssign:
+		fallthrough
+	case lexer.TokenPlusAssign:
+		fallthrough
+	case lexer.TokenMinusAssign:
+		fallthrough
+	case lexer.TokenStarAssign:
+		fallthrough
+	case lexer.TokenSlashAssign:
+		fallthrough
+	case lexer.TokenPercentAssign:
+		fallthrough
+	case lexer.TokenAmpersandAssign:
+		fallthrough
+	case lexer.TokenPipeAssign:
+		fallthrough
+	case lexer.TokenCaretAssign:
+		fallthrough
+	case lexer.TokenShiftLeftAssign:
+		fallthrough
+	case lexer.TokenShiftRightAssign:
+		fallthrough
+	case lexer.TokenAndNotAssign:
 		return ParseAssignmentExpr(parser, left, token)
 	case lexer.TokenDeclAssign:
 		return ParseDeclAssignExpr(parser, left, token)
+	case lexer.TokenArrow:
+		return ParseSendExpr(parser, left, token)
 	case lexer.TokenComma:
 		return ParseListExpr(parser, left, token)
 	default:
@@ -13,14 +13,23 @@ type Parser struct {
 	Stmts  []ast.Stmt
 	Tokens []lexer.Token
 	Pos    int
+	// InHeader is set while parsing the header of an if, for or switch,
+	// where a brace after a type name starts the block instead of a
+	// composite literal.
+	InHeader bool
+	// InBlock is set while parsing the statements of a block, where func
+	// starts a function literal instead of a function declaration.
+	InBlock bool
 }
 
 func NewParser() *Parser {
 	return &Parser{
-		Source: "",
-		Stmts:  make([]ast.Stmt, 0),
-		Tokens: make([]lexer.Token, 0),
-		Pos:    0,
+		Source:   "",
+		Stmts:    make([]ast.Stmt, 0),
+		Tokens:   make([]lexer.Token, 0),
+		Pos:      0,
+		InHeader: false,
+		InBlock:  false,
 	}
 }
 
@@ -29,6 +38,13 @@ func (parser *Parser) Peek() lexer.Token {
 	return token
 }
 
+func (parser *Parser) PeekAhead(n int) lexer.Token {
+	if parser.Pos+n >= len(parser.Tokens) {
+		return parser.Tokens[len(parser.Tokens)-1]
+	}
+	return parser.Tokens[parser.Pos+n]
+}
+
 func (parser *Parser) Advance() lexer.Token {
 	token := parser.Tokens[parser.Pos]
 	parser.Pos += 1
@@ -7,6 +7,10 @@ import (
 
 func ParseBlockStmt(parser *Parser) ast.Stmt {
 	parser.Expect(lexer.TokenBraceOpen)
+	inHeader := parser.InHeader
+	parser.InHeader = false
+	inBlock := parser.InBlock
+	parser.InBlock = true
 
 	blockStmt := ast.BlockStmt{
 		Body: make([]ast.Stmt, 0),
@@ -28,6 +32,8 @@ func ParseBlockStmt(parser *Parser) ast.Stmt {
 			parser.Advance()
 		}
 	}
+	parser.InHeader = inHeader
+	parser.InBlock = inBlock
 
 	return blockStmt
 }
@@ -70,19 +76,82 @@ func ParseImportStmt(parser *Parser) ast.Stmt {
 	}
 }
 
+func ParseDirectiveStmt(parser *Parser) ast.Stmt {
+	directive := parser.Expect(lexer.TokenDirective)
+	if parser.Peek().Type != lexer.TokenEOF {
+		parser.Expect(lexer.TokenNewLine)
+	}
+	return ast.DirectiveStmt{
+		Directive: directive,
+	}
+}
+
 func ParseFuncDeclStmt(parser *Parser) ast.Stmt {
 	parser.Expect(lexer.TokenFunc)
 	funcDeclStmt := ast.FuncDeclStmt{}
+	if parser.Peek().Type == lexer.TokenParenOpen {
+		funcDeclStmt.Receiver = ParseReceiver(parser)
+	}
 	funcDeclStmt.Name = parser.Expect(lexer.TokenIdentifier)
+	if parser.Peek().Type == lexer.TokenBracketOpen {
+		funcDeclStmt.TypeParams = ParseTypeParams(parser)
+	}
+	funcDeclStmt.Parameters = ParseParameters(parser)
+	funcDeclStmt.Results = ParseResults(parser)
+
+	// parse function block
+	funcDeclStmt.Block = ParseBlockStmt(parser)
+
+	return funcDeclStmt
+}
+
+func ParseTypeDeclStmt(parser *Parser) ast.Stmt {
+	parser.Expect(lexer.TokenTypeKeyword)
+	typeDeclStmt := ast.TypeDeclStmt{}
+	typeDeclStmt.Name = parser.Expect(lexer.TokenIdentifier)
+	if IsTypeParams(parser) {
+		typeDeclStmt.TypeParams = ParseTypeParams(parser)
+	}
+	typeDeclStmt.Type = ParseTypeExpr(parser)
+	return typeDeclStmt
+}
+
+// ParseReceiver parses the receiver of a method, which may be unnamed.
+func ParseReceiver(parser *Parser) *ast.FuncParameter {
+	parser.Expect(lexer.TokenParenOpen)
+	receiver := ast.FuncParameter{}
+	receiver.Names = make([]lexer.Token, 0)
+	if parser.Peek().Type == lexer.TokenIdentifier {
+		switch parser.PeekAhead(1).Type {
+		case lexer.TokenParenClose, lexer.TokenDot, lexer.TokenBracketOpen:
+		default:
+			receiver.Names = append(receiver.Names, parser.Advance())
+		}
+	}
+	receiver.Type = ParseTypeExpr(parser)
+	parser.Expect(lexer.TokenParenClose)
+	return &receiver
+}
 
-	// parse parameters
+// ParseParameters parses a parameter list, which is either a list of types or
+// a list of names with their types, such as (a, b int, rest ...string).
+func ParseParameters(parser *Parser) []ast.FuncParameter {
 	parser.Expect(lexer.TokenParenOpen)
-	funcDeclStmt.Parameters = make([]ast.FuncParameter, 0)
+	parameters := make([]ast.FuncParameter, 0)
+	named := false
 	for parser.Peek().Type != lexer.TokenParenClose {
 		param := ast.FuncParameter{}
-		param.Name = parser.Expect(lexer.TokenIdentifier)
-		param.Type = parser.Expect(lexer.TokenIdentifier)
-		funcDeclStmt.Parameters = append(funcDeclStmt.Parameters, param)
+		param.Names = make([]lexer.Token, 0)
+		if IsParameterName(parser) {
+			param.Names = append(param.Names, parser.Advance())
+			named = true
+		}
+		if parser.Peek().Type == lexer.TokenEllipsis {
+			parser.Advance()
+			param.Variadic = true
+		}
+		param.Type = ParseTypeExpr(parser)
+		parameters = append(parameters, param)
 		if parser.Peek().Type == lexer.TokenComma {
 			parser.Advance()
 			continue
@@ -92,58 +161,426 @@ func ParseFuncDeclStmt(parser *Parser) ast.Stmt {
 			parser.InvalidToken(parser.Advance())
 		}
 	}
-	parser.Advance()
+	parenClose := parser.Advance()
+	if !named {
+		return parameters
+	}
+	// the types without a name are the names of the next group
+	groups := make([]ast.FuncParameter, 0)
+	names := make([]lexer.Token, 0)
+	for _, param := range parameters {
+		if len(param.Names) > 0 {
+			param.Names = append(names, param.Names...)
+			groups = append(groups, param)
+			names = make([]lexer.Token, 0)
+			continue
+		}
+		namedType, isNamedType := param.Type.(ast.NamedType)
+		if !isNamedType || namedType.Package.Value != "" || len(namedType.TypeArgs) > 0 || param.Variadic {
+			parser.InvalidToken(parenClose)
+		}
+		names = append(names, namedType.Name)
+	}
+	if len(names) > 0 {
+		parser.InvalidToken(parenClose)
+	}
+	return groups
+}
 
-	// parse return types
-	funcDeclStmt.ReturnTypes = make([]lexer.Token, 0)
-	if parser.Peek().Type == lexer.TokenIdentifier {
-		retType := parser.Advance()
-		funcDeclStmt.ReturnTypes = append(funcDeclStmt.ReturnTypes, retType)
-	} else if parser.Peek().Type == lexer.TokenParenOpen {
-		parser.Advance()
-		for parser.Peek().Type != lexer.TokenParenClose {
-			retType := parser.Expect(lexer.TokenIdentifier)
-			funcDeclStmt.ReturnTypes = append(funcDeclStmt.ReturnTypes, retType)
-			if parser.Peek().Type == lexer.TokenComma {
-				parser.Advance()
-				continue
-			} else if parser.Peek().Type == lexer.TokenParenClose {
-				break
-			} else {
-				parser.InvalidToken(parser.Advance())
+// IsParameterName reports whether the identifier at the current position is
+// the name of a parameter, that is whether it is followed by a type.
+func IsParameterName(parser *Parser) bool {
+	if parser.Peek().Type != lexer.TokenIdentifier {
+		return false
+	}
+	switch parser.PeekAhead(1).Type {
+	case lexer.TokenIdentifier, lexer.TokenStar, lexer.TokenArrow, lexer.TokenEllipsis,
+		lexer.TokenMap, lexer.TokenChan, lexer.TokenFunc, lexer.TokenStruct, lexer.TokenInterface:
+		return true
+	case lexer.TokenBracketOpen:
+		// a name followed by a slice or array type, or an instantiated
+		// generic type such as Stack[T] without a name
+		depth := 0
+		for i := 1; ; i++ {
+			switch parser.PeekAhead(i).Type {
+			case lexer.TokenBracketOpen:
+				depth += 1
+			case lexer.TokenBracketClose:
+				depth -= 1
+				if depth == 0 {
+					next := parser.PeekAhead(i + 1).Type
+					return next != lexer.TokenComma && next != lexer.TokenParenClose
+				}
+			case lexer.TokenEOF:
+				return false
 			}
 		}
-		parser.Advance()
+	default:
+		return false
 	}
+}
 
-	// parse function block
-	funcDeclStmt.Block = ParseBlockStmt(parser)
-
-	return funcDeclStmt
+// ParseResults parses the results of a function, which are either a single
+// type or a parameter list.
+func ParseResults(parser *Parser) []ast.FuncParameter {
+	if parser.Peek().Type == lexer.TokenParenOpen {
+		return ParseParameters(parser)
+	}
+	results := make([]ast.FuncParameter, 0)
+	if StartsType(parser.Peek()) {
+		results = append(results, ast.FuncParameter{
+			Names: make([]lexer.Token, 0),
+			Type:  ParseTypeExpr(parser),
+		})
+	}
+	return results
 }
 
 func ParseVarDeclStmt(parser *Parser) ast.Stmt {
 	parser.Expect(lexer.TokenVar)
 	varDeclStmt := ast.VarDeclStmt{}
 	varDeclStmt.Name = parser.Expect(lexer.TokenIdentifier)
-	varDeclStmt.Type = parser.Expect(lexer.TokenIdentifier)
+	varDeclStmt.Type = ParseTypeExpr(parser)
 	return varDeclStmt
 }
 
 func ParseReturnStmt(parser *Parser) ast.Stmt {
 	returnStmt := ast.ReturnStmt{}
 	parser.Expect(lexer.TokenReturn)
-	if parser.Peek().Type == lexer.TokenNewLine {
+	switch parser.Peek().Type {
+	case lexer.TokenNewLine, lexer.TokenSemicolon:
 		parser.Advance()
 		return returnStmt
+	case lexer.TokenBraceClose:
+		return returnStmt
 	}
 	returnStmt.Values = ParseExpr(parser, 0)
 	return returnStmt
 }
 
+func ParseIfStmt(parser *Parser) ast.Stmt {
+	ifStmt := ast.IfStmt{}
+	ifStmt.If = parser.Expect(lexer.TokenIf)
+	inHeader := parser.InHeader
+	parser.InHeader = true
+	cond := ParseExpr(parser, 0)
+	if parser.Peek().Type == lexer.TokenSemicolon {
+		parser.Advance()
+		ifStmt.Init = ast.ExprStmt{
+			Expr: cond,
+		}
+		cond = ParseExpr(parser, 0)
+	}
+	parser.InHeader = inHeader
+	ifStmt.Cond = cond
+	ifStmt.Block = ParseBlockStmt(parser)
+	if parser.Peek().Type == lexer.TokenElse {
+		parser.Advance()
+		if parser.Peek().Type == lexer.TokenIf {
+			ifStmt.Else = ParseIfStmt(parser)
+		} else {
+			ifStmt.Else = ParseBlockStmt(parser)
+		}
+	}
+	return ifStmt
+}
+
+func ParseForStmt(parser *Parser, label lexer.Token) ast.Stmt {
+	inHeader := parser.InHeader
+	parser.InHeader = true
+	forStmt := ParseForClause(parser, label)
+	parser.InHeader = inHeader
+	return forStmt
+}
+
+// ParseForClause parses a for loop with the parser in the header state. The
+// block of the loop resets the state.
+func ParseForClause(parser *Parser, label lexer.Token) ast.Stmt {
+	forToken := parser.Expect(lexer.TokenFor)
+	if IsRangeClause(parser) {
+		return ParseRangeStmt(parser, forToken, label)
+	}
+	forStmt := ast.ForStmt{}
+	forStmt.For = forToken
+	forStmt.Label = label
+	if parser.Peek().Type == lexer.TokenBraceOpen {
+		forStmt.Block = ParseBlockStmt(parser)
+		return forStmt
+	}
+	var init ast.Stmt
+	if parser.Peek().Type != lexer.TokenSemicolon {
+		init = ParseExprStmt(parser)
+	}
+	if parser.Peek().Type != lexer.TokenSemicolon {
+		exprStmt, isExprStmt := init.(ast.ExprStmt)
+		if !isExprStmt {
+			parser.InvalidToken(parser.Peek())
+		}
+		forStmt.Cond = exprStmt.Expr
+		forStmt.Block = ParseBlockStmt(parser)
+		return forStmt
+	}
+	parser.Advance()
+	forStmt.Init = init
+	if parser.Peek().Type != lexer.TokenSemicolon {
+		forStmt.Cond = ParseExpr(parser, 0)
+	}
+	parser.Expect(lexer.TokenSemicolon)
+	if parser.Peek().Type != lexer.TokenBraceOpen {
+		forStmt.Post = ParseExprStmt(parser)
+	}
+	forStmt.Block = ParseBlockStmt(parser)
+	return forStmt
+}
+
+// IsRangeClause reports whether the header of the for loop being parsed is a
+// range clause.
+func IsRangeClause(parser *Parser) bool {
+	for n := 0; ; n++ {
+		switch parser.PeekAhead(n).Type {
+		case lexer.TokenRange:
+			return true
+		case lexer.TokenBraceOpen, lexer.TokenSemicolon, lexer.TokenNewLine, lexer.TokenEOF:
+			return false
+		}
+	}
+}
+
+func ParseRangeStmt(parser *Parser, forToken lexer.Token, label lexer.Token) ast.Stmt {
+	rangeStmt := ast.RangeStmt{}
+	rangeStmt.For = forToken
+	rangeStmt.Label = label
+	if parser.Peek().Type != lexer.TokenRange {
+		rangeStmt.Key = ParseExpr(parser, 2)
+		if parser.Peek().Type == lexer.TokenComma {
+			parser.Advance()
+			rangeStmt.Value = ParseExpr(parser, 2)
+		}
+		token := parser.Advance()
+		switch token.Type {
+		case lexer.TokenDeclAssign:
+			rangeStmt.Declare = true
+		case lexer.TokenAssign:
+			rangeStmt.Declare = false
+		default:
+			parser.InvalidToken(token)
+		}
+	}
+	parser.Expect(lexer.TokenRange)
+	rangeStmt.Range = ParseExpr(parser, 0)
+	rangeStmt.Block = ParseBlockStmt(parser)
+	return rangeStmt
+}
+
+func ParseSwitchStmt(parser *Parser, label lexer.Token) ast.Stmt {
+	switchStmt := ast.SwitchStmt{}
+	switchStmt.Switch = parser.Expect(lexer.TokenSwitch)
+	switchStmt.Label = label
+	inHeader := parser.InHeader
+	parser.InHeader = true
+	if parser.Peek().Type != lexer.TokenBraceOpen {
+		var tag ast.Expr
+		if parser.Peek().Type != lexer.TokenSemicolon {
+			tag = ParseExpr(parser, 0)
+		}
+		if parser.Peek().Type == lexer.TokenSemicolon {
+			parser.Advance()
+			switchStmt.Init = ast.ExprStmt{
+				Expr: tag,
+			}
+			tag = nil
+			if parser.Peek().Type != lexer.TokenBraceOpen {
+				tag = ParseExpr(parser, 0)
+			}
+		}
+		switchStmt.Tag = tag
+	}
+	parser.InHeader = inHeader
+	if typeSwitchStmt, isTypeSwitch := AsTypeSwitch(switchStmt); isTypeSwitch {
+		typeSwitchStmt.Cases = ParseCaseClauses(parser, ParseTypeList)
+		return typeSwitchStmt
+	}
+	switchStmt.Cases = ParseCaseClauses(parser, func(parser *Parser) ast.Expr {
+		return ParseExpr(parser, 0)
+	})
+	return switchStmt
+}
+
+// AsTypeSwitch converts a switch whose tag is a x.(type) guard into a type
+// switch.
+func AsTypeSwitch(switchStmt ast.SwitchStmt) (ast.TypeSwitchStmt, bool) {
+	typeSwitchStmt := ast.TypeSwitchStmt{
+		Switch: switchStmt.Switch,
+		Label:  switchStmt.Label,
+		Init:   switchStmt.Init,
+	}
+	guard := switchStmt.Tag
+	if declAssignExpr, isDeclAssignExpr := guard.(ast.DeclAssignExpr); isDeclAssignExpr {
+		symbolExpr, isSymbolExpr := declAssignExpr.Left.(ast.SymbolExpr)
+		if !isSymbolExpr {
+			return typeSwitchStmt, false
+		}
+		typeSwitchStmt.Binding = symbolExpr.Symbol
+		guard = declAssignExpr.Right
+	}
+	typeAssertExpr, isTypeAssertExpr := guard.(ast.TypeAssertExpr)
+	if !isTypeAssertExpr || typeAssertExpr.Type != nil {
+		return typeSwitchStmt, false
+	}
+	typeSwitchStmt.Value = typeAssertExpr.Value
+	return typeSwitchStmt, true
+}
+
+// ParseCaseClauses parses the block of a switch. parseValues parses the
+// values of a case.
+func ParseCaseClauses(parser *Parser, parseValues func(parser *Parser) ast.Expr) []ast.CaseClause {
+	parser.Expect(lexer.TokenBraceOpen)
+	cases := make([]ast.CaseClause, 0)
+	for {
+		nextToken := parser.Advance()
+		switch nextToken.Type {
+		case lexer.TokenNewLine:
+			continue
+		case lexer.TokenBraceClose:
+			return cases
+		case lexer.TokenCase:
+			cases = append(cases, ParseCaseClause(parser, nextToken, parseValues(parser)))
+		case lexer.TokenDefault:
+			cases = append(cases, ParseCaseClause(parser, nextToken, nil))
+		default:
+			parser.InvalidToken(nextToken)
+		}
+	}
+}
+
+// ParseTypeList parses a comma separated list of types.
+func ParseTypeList(parser *Parser) ast.Expr {
+	_type := ParseTypeOperand(parser)
+	if parser.Peek().Type != lexer.TokenComma {
+		return _type
+	}
+	parser.Advance()
+	return ast.ListExpr{
+		Value: _type,
+		Next:  ParseTypeList(parser),
+	}
+}
+
+// ParseCaseClause parses the colon and the body of a case clause whose values
+// have already been parsed.
+func ParseCaseClause(parser *Parser, caseToken lexer.Token, values ast.Expr) ast.CaseClause {
+	parser.Expect(lexer.TokenColon)
+	caseClause := ast.CaseClause{
+		Case:   caseToken,
+		Values: values,
+		Body:   make([]ast.Stmt, 0),
+	}
+	for {
+		nextToken := parser.Peek()
+		switch nextToken.Type {
+		case lexer.TokenEOF:
+			panic("reached unexpected EOF")
+		case lexer.TokenCase, lexer.TokenDefault, lexer.TokenBraceClose:
+			return caseClause
+		}
+		stmt := ParseStmt(parser, nextToken)
+		if stmt != nil {
+			caseClause.Body = append(caseClause.Body, stmt)
+		} else {
+			parser.Advance()
+		}
+	}
+}
+
+// ParseSelectStmt parses a select statement and its communication cases.
+func ParseSelectStmt(parser *Parser, label lexer.Token) ast.Stmt {
+	selectStmt := ast.SelectStmt{}
+	selectStmt.Select = parser.Expect(lexer.TokenSelect)
+	selectStmt.Label = label
+	selectStmt.Cases = ParseCaseClauses(parser, ParseCommExpr)
+	return selectStmt
+}
+
+// ParseCommExpr parses the send or receive of a select case.
+func ParseCommExpr(parser *Parser) ast.Expr {
+	comm := ParseExpr(parser, 0)
+	receive := comm
+	switch expr := comm.(type) {
+	case ast.SendExpr:
+		return comm
+	case ast.AssignmentExpr:
+		receive = expr.Right
+	case ast.DeclAssignExpr:
+		receive = expr.Right
+	}
+	if _, isReceiveExpr := receive.(ast.ReceiveExpr); !isReceiveExpr {
+		parser.InvalidToken(parser.Peek())
+	}
+	return comm
+}
+
+func ParseDeferStmt(parser *Parser) ast.Stmt {
+	deferStmt := ast.DeferStmt{}
+	deferStmt.Defer = parser.Expect(lexer.TokenDefer)
+	deferStmt.Call = ParseDeferredCall(parser, deferStmt.Defer)
+	return deferStmt
+}
+
+func ParseGoStmt(parser *Parser) ast.Stmt {
+	goStmt := ast.GoStmt{}
+	goStmt.Go = parser.Expect(lexer.TokenGo)
+	goStmt.Call = ParseDeferredCall(parser, goStmt.Go)
+	return goStmt
+}
+
+// ParseDeferredCall parses the function call of a defer or go statement.
+func ParseDeferredCall(parser *Parser, keyword lexer.Token) ast.Expr {
+	call := ParseExpr(parser, 0)
+	if _, isFuncCallExpr := call.(ast.FuncCallExpr); !isFuncCallExpr {
+		parser.InvalidToken(keyword)
+	}
+	return call
+}
+
+func ParseBranchStmt(parser *Parser) ast.Stmt {
+	branchStmt := ast.BranchStmt{}
+	branchStmt.Keyword = parser.Advance()
+	if parser.Peek().Type == lexer.TokenIdentifier {
+		branchStmt.Label = parser.Advance()
+	}
+	return branchStmt
+}
+
+// ParseLabeledStmt parses a label and the loop or switch it labels.
+func ParseLabeledStmt(parser *Parser) ast.Stmt {
+	label := parser.Expect(lexer.TokenIdentifier)
+	parser.Expect(lexer.TokenColon)
+	for parser.Peek().Type == lexer.TokenNewLine {
+		parser.Advance()
+	}
+	switch parser.Peek().Type {
+	case lexer.TokenFor:
+		return ParseForStmt(parser, label)
+	case lexer.TokenSwitch:
+		return ParseSwitchStmt(parser, label)
+	case lexer.TokenSelect:
+		return ParseSelectStmt(parser, label)
+	default:
+		parser.InvalidToken(parser.Peek())
+		return nil
+	}
+}
+
 func ParseExprStmt(parser *Parser) ast.Stmt {
-	bindingPower := BindingPower(parser, parser.Peek())
-	expr := ParseExpr(parser, bindingPower)
+	expr := ParseExpr(parser, 0)
+
+	switch parser.Peek().Type {
+	case lexer.TokenIncrement, lexer.TokenDecrement:
+		return ast.IncDecStmt{
+			Value:    expr,
+			Operator: parser.Advance(),
+		}
+	}
 
 	return ast.ExprStmt{
 		Expr: expr,
@@ -153,19 +590,55 @@ func ParseExprStmt(parser *Parser) ast.Stmt {
 func ParseStmt(parser *Parser, token lexer.Token) ast.Stmt {
 	switch token.Type {
 	case lexer.TokenNewLine:
+		fallthrough
+	case lexer.TokenSemicolon:
 		return nil
 	case lexer.TokenIdentifier:
+		if parser.PeekAhead(1).Type == lexer.TokenColon {
+			return ParseLabeledStmt(parser)
+		}
+		return ParseExprStmt(parser)
+	case lexer.TokenArrow:
+		fallthrough
+	case lexer.TokenStar:
+		fallthrough
+	case lexer.TokenParenOpen:
 		return ParseExprStmt(parser)
 	case lexer.TokenPackage:
 		return ParsePackageStmt(parser)
 	case lexer.TokenImport:
 		return ParseImportStmt(parser)
+	case lexer.TokenDirective:
+		return ParseDirectiveStmt(parser)
 	case lexer.TokenFunc:
+		if parser.InBlock {
+			return ParseExprStmt(parser)
+		}
 		return ParseFuncDeclStmt(parser)
+	case lexer.TokenTypeKeyword:
+		return ParseTypeDeclStmt(parser)
 	case lexer.TokenVar:
 		return ParseVarDeclStmt(parser)
 	case lexer.TokenReturn:
 		return ParseReturnStmt(parser)
+	case lexer.TokenIf:
+		return ParseIfStmt(parser)
+	case lexer.TokenFor:
+		return ParseForStmt(parser, lexer.Token{})
+	case lexer.TokenSwitch:
+		return ParseSwitchStmt(parser, lexer.Token{})
+	case lexer.TokenSelect:
+		return ParseSelectStmt(parser, lexer.Token{})
+	case lexer.TokenBreak:
+		fallthrough
+	case lexer.TokenContinue:
+		fallthrough
+	case lexer.TokenFallthrough:
+		return ParseBranchStmt(parser)
+	case lexer.TokenDefer:
+		return ParseDeferStmt(parser)
+	case lexer.TokenGo:
+		return ParseGoStmt(parser)
 	default:
 		parser.InvalidToken(token)
 		return nil
@@ -0,0 +1,256 @@
+package parser
+
+import (
+	"github.com/tobiashort/gox/ast"
+	"github.com/tobiashort/gox/lexer"
+)
+
+func ParseTypeExpr(parser *Parser) ast.TypeExpr {
+	return ParseTypeExprAt(parser, parser.Advance())
+}
+
+// ParseTypeExprAt parses a type starting with the given token, which has
+// already been consumed.
+func ParseTypeExprAt(parser *Parser, token lexer.Token) ast.TypeExpr {
+	switch token.Type {
+	case lexer.TokenIdentifier:
+		return ParseNamedType(parser, token)
+	case lexer.TokenStar:
+		return ast.PointerType{
+			Star: token,
+			Elem: ParseTypeExpr(parser),
+		}
+	case lexer.TokenBracketOpen:
+		if parser.Peek().Type == lexer.TokenBracketClose {
+			parser.Advance()
+			return ast.SliceType{
+				BracketOpen: token,
+				Elem:        ParseTypeExpr(parser),
+			}
+		}
+		arrayType := ast.ArrayType{}
+		arrayType.BracketOpen = token
+		if parser.Peek().Type == lexer.TokenEllipsis {
+			// the length of [...]T is the number of elements of the
+			// composite literal that must follow
+			ellipsis := parser.Advance()
+			arrayType.Ellipsis = true
+			parser.Expect(lexer.TokenBracketClose)
+			arrayType.Elem = ParseTypeExpr(parser)
+			if parser.Peek().Type != lexer.TokenBraceOpen {
+				parser.InvalidToken(ellipsis)
+			}
+			return arrayType
+		}
+		arrayType.Len = ParseNestedExpr(parser, 0)
+		parser.Expect(lexer.TokenBracketClose)
+		arrayType.Elem = ParseTypeExpr(parser)
+		return arrayType
+	case lexer.TokenMap:
+		mapType := ast.MapType{}
+		mapType.Map = token
+		parser.Expect(lexer.TokenBracketOpen)
+		mapType.Key = ParseTypeExpr(parser)
+		parser.Expect(lexer.TokenBracketClose)
+		mapType.Value = ParseTypeExpr(parser)
+		return mapType
+	case lexer.TokenChan:
+		chanType := ast.ChanType{}
+		chanType.Chan = token
+		if parser.Peek().Type == lexer.TokenArrow {
+			parser.Advance()
+			chanType.Dir = ast.ChanSend
+		}
+		chanType.Value = ParseTypeExpr(parser)
+		return chanType
+	case lexer.TokenArrow:
+		chanType := ast.ChanType{}
+		chanType.Chan = parser.Expect(lexer.TokenChan)
+		chanType.Dir = ast.ChanRecv
+		chanType.Value = ParseTypeExpr(parser)
+		return chanType
+	case lexer.TokenFunc:
+		funcType := ast.FuncType{}
+		funcType.Func = token
+		funcType.Parameters = ParseParameters(parser)
+		funcType.Results = ParseResults(parser)
+		return funcType
+	case lexer.TokenStruct:
+		return ParseStructType(parser, token)
+	case lexer.TokenInterface:
+		return ParseInterfaceType(parser, token)
+	default:
+		parser.InvalidToken(token)
+		return nil
+	}
+}
+
+// StartsType reports whether a type starts with the given token.
+func StartsType(token lexer.Token) bool {
+	switch token.Type {
+	case lexer.TokenIdentifier, lexer.TokenStar, lexer.TokenBracketOpen, lexer.TokenArrow,
+		lexer.TokenMap, lexer.TokenChan, lexer.TokenFunc, lexer.TokenStruct, lexer.TokenInterface:
+		return true
+	default:
+		return false
+	}
+}
+
+func ParseNamedType(parser *Parser, token lexer.Token) ast.TypeExpr {
+	namedType := ast.NamedType{}
+	namedType.Name = token
+	if parser.Peek().Type == lexer.TokenDot {
+		parser.Advance()
+		namedType.Package = token
+		namedType.Name = parser.Expect(lexer.TokenIdentifier)
+	}
+	if parser.Peek().Type == lexer.TokenBracketOpen {
+		namedType.TypeArgs = ParseTypeArgs(parser)
+	}
+	return namedType
+}
+
+// ParseTypeArgs parses the type arguments of an instantiated generic type,
+// such as [int, string].
+func ParseTypeArgs(parser *Parser) []ast.TypeExpr {
+	parser.Expect(lexer.TokenBracketOpen)
+	typeArgs := make([]ast.TypeExpr, 0)
+	for {
+		typeArgs = append(typeArgs, ParseTypeExpr(parser))
+		if parser.Peek().Type != lexer.TokenComma {
+			parser.Expect(lexer.TokenBracketClose)
+			return typeArgs
+		}
+		parser.Advance()
+	}
+}
+
+// ParseTypeParams parses the type parameters of a generic function or type,
+// such as [K comparable, V any].
+func ParseTypeParams(parser *Parser) []ast.TypeParam {
+	parser.Expect(lexer.TokenBracketOpen)
+	typeParams := make([]ast.TypeParam, 0)
+	for {
+		typeParam := ast.TypeParam{}
+		typeParam.Names = []lexer.Token{parser.Expect(lexer.TokenIdentifier)}
+		for parser.Peek().Type == lexer.TokenComma {
+			parser.Advance()
+			typeParam.Names = append(typeParam.Names, parser.Expect(lexer.TokenIdentifier))
+		}
+		typeParam.Constraint = ParseTypeTerms(parser)
+		typeParams = append(typeParams, typeParam)
+		if parser.Peek().Type != lexer.TokenComma {
+			parser.Expect(lexer.TokenBracketClose)
+			return typeParams
+		}
+		parser.Advance()
+	}
+}
+
+// IsTypeParams reports whether the bracket after the name of a type
+// declaration starts type parameters rather than an array type. The length
+// of an array is a single expression, while a type parameter is followed by
+// another name or by its constraint.
+func IsTypeParams(parser *Parser) bool {
+	if parser.Peek().Type != lexer.TokenBracketOpen || parser.PeekAhead(1).Type != lexer.TokenIdentifier {
+		return false
+	}
+	switch parser.PeekAhead(2).Type {
+	case lexer.TokenBracketClose, lexer.TokenDot, lexer.TokenPlus, lexer.TokenMinus, lexer.TokenStar, lexer.TokenSlash,
+		lexer.TokenPercent, lexer.TokenShiftLeft, lexer.TokenShiftRight, lexer.TokenAmpersand, lexer.TokenAndNot, lexer.TokenCaret:
+		return false
+	default:
+		return true
+	}
+}
+
+func ParseStructType(parser *Parser, token lexer.Token) ast.TypeExpr {
+	structType := ast.StructType{}
+	structType.Struct = token
+	structType.Fields = make([]ast.StructField, 0)
+	parser.Expect(lexer.TokenBraceOpen)
+	for {
+		switch parser.Peek().Type {
+		case lexer.TokenNewLine, lexer.TokenSemicolon:
+			parser.Advance()
+			continue
+		case lexer.TokenBraceClose:
+			parser.Advance()
+			return structType
+		}
+		field := ast.StructField{}
+		field.Names = make([]lexer.Token, 0)
+		if !IsEmbeddedField(parser) {
+			field.Names = append(field.Names, parser.Expect(lexer.TokenIdentifier))
+			for parser.Peek().Type == lexer.TokenComma {
+				parser.Advance()
+				field.Names = append(field.Names, parser.Expect(lexer.TokenIdentifier))
+			}
+		}
+		field.Type = ParseTypeExpr(parser)
+		if parser.Peek().Type == lexer.TokenString || parser.Peek().Type == lexer.TokenRawString {
+			field.Tag = parser.Advance()
+		}
+		structType.Fields = append(structType.Fields, field)
+	}
+}
+
+func ParseInterfaceType(parser *Parser, token lexer.Token) ast.TypeExpr {
+	interfaceType := ast.InterfaceType{}
+	interfaceType.Interface = token
+	interfaceType.Elems = make([]ast.InterfaceElem, 0)
+	parser.Expect(lexer.TokenBraceOpen)
+	for {
+		switch parser.Peek().Type {
+		case lexer.TokenNewLine, lexer.TokenSemicolon:
+			parser.Advance()
+			continue
+		case lexer.TokenBraceClose:
+			parser.Advance()
+			return interfaceType
+		}
+		elem := ast.InterfaceElem{}
+		if parser.Peek().Type == lexer.TokenIdentifier && parser.PeekAhead(1).Type == lexer.TokenParenOpen {
+			elem.Name = parser.Advance()
+			elem.Parameters = ParseParameters(parser)
+			elem.Results = ParseResults(parser)
+		} else {
+			elem.Terms = ParseTypeTerms(parser)
+		}
+		interfaceType.Elems = append(interfaceType.Elems, elem)
+	}
+}
+
+// ParseTypeTerms parses a union of type terms such as ~int | ~string, or a
+// single type.
+func ParseTypeTerms(parser *Parser) []ast.TypeTerm {
+	terms := make([]ast.TypeTerm, 0)
+	for {
+		term := ast.TypeTerm{}
+		if parser.Peek().Type == lexer.TokenTilde {
+			parser.Advance()
+			term.Tilde = true
+		}
+		term.Type = ParseTypeExpr(parser)
+		terms = append(terms, term)
+		if parser.Peek().Type != lexer.TokenPipe {
+			return terms
+		}
+		parser.Advance()
+	}
+}
+
+// IsEmbeddedField reports whether the struct field being parsed is an
+// embedded field, that is a possibly qualified type name or a pointer to it
+// without a field name.
+func IsEmbeddedField(parser *Parser) bool {
+	if parser.Peek().Type == lexer.TokenStar {
+		return true
+	}
+	switch parser.PeekAhead(1).Type {
+	case lexer.TokenDot, lexer.TokenNewLine, lexer.TokenSemicolon, lexer.TokenBraceClose, lexer.TokenString, lexer.TokenRawString:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,108 @@
+package transpiler
+
+// Scope is a lexical scope of the transpiled code. It maps the names declared
+// in the scope to their type, which is empty if the type is unknown.
+type Scope struct {
+	Parent *Scope
+	Names  map[string]string
+	// Reserved are the names declared further down in the block of the
+	// scope. Generated code must not declare them before the user does.
+	Reserved map[string]bool
+	// Reusable maps a type to the variable of that type, such as the error
+	// of a checked call, that generated code can reuse in the scope.
+	Reusable map[string]string
+	// Func is set for the outermost scope of a function body. Variables of
+	// an enclosing function are not reused by generated code past it.
+	Func bool
+}
+
+func NewScope(parent *Scope) *Scope {
+	return &Scope{
+		Parent:   parent,
+		Names:    make(map[string]string),
+		Reserved: make(map[string]bool),
+		Reusable: make(map[string]string),
+		Func:     false,
+	}
+}
+
+// NewFuncScope returns the outermost scope of a function body.
+func NewFuncScope(parent *Scope) *Scope {
+	scope := NewScope(parent)
+	scope.Func = true
+	return scope
+}
+
+func (scope *Scope) Declare(name string, _type string) {
+	if name == "_" {
+		return
+	}
+	scope.Names[name] = _type
+}
+
+func (scope *Scope) Reserve(name string) {
+	scope.Reserved[name] = true
+}
+
+// Lookup returns the type of the name declared in this scope or any of its
+// parents.
+func (scope *Scope) Lookup(name string) (string, bool) {
+	for ; scope != nil; scope = scope.Parent {
+		if _type, exists := scope.Names[name]; exists {
+			return _type, true
+		}
+	}
+	return "", false
+}
+
+// LookupReusable returns the reusable variable of the given type in this
+// scope or the closest parent of the same function that has one, unless a
+// scope in between declares or reserves a variable of the same name.
+func (scope *Scope) LookupReusable(_type string) (string, bool) {
+	for inner := scope; inner != nil; inner = inner.Parent {
+		if reusable, exists := inner.Reusable[_type]; exists {
+			for shadowing := scope; shadowing != inner; shadowing = shadowing.Parent {
+				if shadowing.IsLocal(reusable) || shadowing.Reserved[reusable] {
+					return "", false
+				}
+			}
+			return reusable, true
+		}
+		if inner.Func {
+			break
+		}
+	}
+	return "", false
+}
+
+func (scope *Scope) IsDeclared(name string) bool {
+	_, exists := scope.Lookup(name)
+	return exists
+}
+
+// IsPackageLevel reports whether name is declared at the top level of the
+// file or predeclared, rather than declared in a function.
+func (scope *Scope) IsPackageLevel(name string) bool {
+	for ; scope.Parent != nil; scope = scope.Parent {
+		if scope.IsLocal(name) {
+			return false
+		}
+	}
+	return true
+}
+
+func (scope *Scope) IsLocal(name string) bool {
+	_, exists := scope.Names[name]
+	return exists
+}
+
+// IsFree reports whether generated code can declare name in this scope
+// without shadowing or clashing with a name of the user.
+func (scope *Scope) IsFree(name string) bool {
+	for ; scope != nil; scope = scope.Parent {
+		if _, exists := scope.Names[name]; exists || scope.Reserved[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -2,7 +2,10 @@ package transpiler
 
 import (
 	"fmt"
+	"path"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/tobiashort/gox/ast"
@@ -10,21 +13,80 @@ import (
 	"github.com/tobiashort/gox/parser"
 )
 
+// Failure strategies of or_panic. Any other value is the name of a handler
+// function that is called with the error.
+const (
+	OnErrorPanic    = "panic"
+	OnErrorLogFatal = "log.Fatal"
+	OnErrorOsExit   = "os.Exit"
+)
+
+var handlerRegex = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*(\\.[A-Za-z_][A-Za-z0-9_]*)?$")
+
 type Transpiler struct {
 	StringBuilder strings.Builder
+	ReturnTypes   []ast.TypeExpr
+	Imports       []string
+	ImportsPos    int
+	OnError       string
+	FileName      string
+	Funcs         map[string]ast.FuncDeclStmt
+	// Methods maps the name of a receiver type and a method, such as
+	// Server.Start, to the declaration of the method.
+	Methods      map[string]ast.FuncDeclStmt
+	Temps        int
+	IgnoreChecks bool
+	// InHeader is set while emitting the header of an if, for or switch,
+	// where composite literals of named types have to be parenthesized.
+	InHeader bool
 }
 
 func NewTranspiler() *Transpiler {
 	return &Transpiler{
 		StringBuilder: strings.Builder{},
+		ReturnTypes:   make([]ast.TypeExpr, 0),
+		Imports:       make([]string, 0),
+		ImportsPos:    0,
+		OnError:       OnErrorPanic,
+		FileName:      "",
+		Funcs:         make(map[string]ast.FuncDeclStmt),
+		Methods:       make(map[string]ast.FuncDeclStmt),
+		Temps:         0,
+		IgnoreChecks:  false,
+		InHeader:      false,
 	}
 }
 
 func (transpiler *Transpiler) Transpile(source string) string {
 	parser := parser.NewParser()
 	parser.Parse(source)
-	locals := make(map[string]bool)
+	for _, stmtInterface := range parser.Stmts {
+		switch stmt := stmtInterface.(type) {
+		case ast.DirectiveStmt:
+			transpiler.ApplyDirective(stmt)
+		case ast.FuncDeclStmt:
+			if stmt.Receiver == nil {
+				transpiler.Funcs[stmt.Name.Value] = stmt
+			} else {
+				transpiler.Methods[ReceiverTypeName(stmt.Receiver.Type)+"."+stmt.Name.Value] = stmt
+			}
+		}
+	}
+	if !handlerRegex.MatchString(transpiler.OnError) {
+		panic(fmt.Sprintf("invalid or_panic failure strategy %q", transpiler.OnError))
+	}
+	locals := NewScope(nil)
+	for name := range transpiler.Funcs {
+		locals.Declare(name, "")
+	}
+	// package-level variables may be used before they are declared
+	for _, stmtInterface := range parser.Stmts {
+		if stmt, isVarDeclStmt := stmtInterface.(ast.VarDeclStmt); isVarDeclStmt {
+			locals.Declare(stmt.Name.Value, transpiler.ExprString(stmt.Type, "", locals))
+		}
+	}
 	transpiler.TranspileWithDepth(parser.Stmts, 0, locals)
+	transpiler.TranspileImports()
 	return strings.TrimSpace(transpiler.StringBuilder.String())
 }
 
@@ -40,132 +102,831 @@ func (transpiler *Transpiler) String() string {
 	return transpiler.StringBuilder.String()
 }
 
+// IsCheckedCall reports whether expr is a function call followed by one of
+// the error handling keywords such as or_panic or or_return.
+func IsCheckedCall(expr ast.Expr) (ast.FuncCallExpr, bool) {
+	funcCallExpr, isFuncCallExpr := expr.(ast.FuncCallExpr)
+	if !isFuncCallExpr {
+		return funcCallExpr, false
+	}
+	return funcCallExpr, funcCallExpr.OrPanic || funcCallExpr.OrReturn || funcCallExpr.OrWrap != nil ||
+		funcCallExpr.OrElse != nil || funcCallExpr.OrElseBlock != nil || len(funcCallExpr.Catches) > 0
+}
+
+// CommaOk returns expr without its or_panic if it is a comma-ok expression,
+// that is an index expression, a type assertion or a receive expression
+// followed by or_panic. It also returns the token to report the location
+// of the expression and the reason of a failure.
+func CommaOk(exprInterface ast.Expr) (ast.Expr, lexer.Token, string, bool) {
+	switch expr := exprInterface.(type) {
+	case ast.IndexExpr:
+		if expr.OrPanic {
+			expr.OrPanic = false
+			return expr, expr.BracketOpen, "key not found", true
+		}
+	case ast.TypeAssertExpr:
+		if expr.OrPanic {
+			expr.OrPanic = false
+			return expr, expr.ParenOpen, "type assertion failed", true
+		}
+	case ast.ReceiveExpr:
+		if expr.OrPanic {
+			expr.OrPanic = false
+			return expr, expr.Arrow, "channel closed", true
+		}
+	}
+	return exprInterface, lexer.Token{}, "", false
+}
+
+// IsChecked reports whether expr is a checked call or a comma-ok expression.
+func IsChecked(expr ast.Expr) bool {
+	_, isCheckedCall := IsCheckedCall(expr)
+	_, _, _, isCommaOk := CommaOk(expr)
+	return isCheckedCall || isCommaOk
+}
+
+// ContainsChecked reports whether expr is or contains a checked call or a
+// comma-ok expression.
+func ContainsChecked(exprInterface ast.Expr) bool {
+	if IsChecked(exprInterface) {
+		return true
+	}
+	switch expr := exprInterface.(type) {
+	case ast.FuncCallExpr:
+		return ContainsChecked(expr.Func) || ContainsChecked(expr.Args)
+	case ast.BinaryExpr:
+		return ContainsChecked(expr.Left) || ContainsChecked(expr.Right)
+	case ast.UnaryExpr:
+		return ContainsChecked(expr.Value)
+	case ast.AccessExpr:
+		return ContainsChecked(expr.Instance)
+	case ast.IndexExpr:
+		return ContainsChecked(expr.Value) || ContainsChecked(expr.Index)
+	case ast.SliceExpr:
+		return ContainsChecked(expr.Value) || ContainsChecked(expr.Low) || ContainsChecked(expr.High) || ContainsChecked(expr.Max)
+	case ast.TypeAssertExpr:
+		return ContainsChecked(expr.Value)
+	case ast.ReceiveExpr:
+		return ContainsChecked(expr.Channel)
+	case ast.SendExpr:
+		return ContainsChecked(expr.Channel) || ContainsChecked(expr.Value)
+	case ast.ListExpr:
+		return ContainsChecked(expr.Value) || ContainsChecked(expr.Next)
+	case ast.CompositeLitExpr:
+		for _, element := range expr.Elements {
+			if ContainsChecked(element.Key) || ContainsChecked(element.Value) {
+				return true
+			}
+		}
+		return false
+	case ast.AssignmentExpr:
+		return ContainsChecked(expr.Left) || ContainsChecked(expr.Right)
+	case ast.DeclAssignExpr:
+		return ContainsChecked(expr.Right)
+	default:
+		return false
+	}
+}
+
+// ContainsCall reports whether evaluating expr calls a function or receives
+// from a channel, which Go does in lexical left-to-right order. The body of
+// a function literal is not evaluated with it.
+func ContainsCall(exprInterface ast.Expr) bool {
+	switch expr := exprInterface.(type) {
+	case ast.FuncCallExpr, ast.ReceiveExpr:
+		return true
+	case ast.BinaryExpr:
+		return ContainsCall(expr.Left) || ContainsCall(expr.Right)
+	case ast.UnaryExpr:
+		return ContainsCall(expr.Value)
+	case ast.AccessExpr:
+		return ContainsCall(expr.Instance)
+	case ast.IndexExpr:
+		return ContainsCall(expr.Value) || ContainsCall(expr.Index)
+	case ast.SliceExpr:
+		return ContainsCall(expr.Value) || ContainsCall(expr.Low) || ContainsCall(expr.High) || ContainsCall(expr.Max)
+	case ast.TypeAssertExpr:
+		return ContainsCall(expr.Value)
+	case ast.ListExpr:
+		return ContainsCall(expr.Value) || ContainsCall(expr.Next)
+	case ast.CompositeLitExpr:
+		for _, element := range expr.Elements {
+			if ContainsCall(element.Key) || ContainsCall(element.Value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// ZeroValue returns the Go zero value literal for the given type.
+func (transpiler *Transpiler) ZeroValue(typeExpr ast.TypeExpr, locals *Scope) string {
+	switch _type := typeExpr.(type) {
+	case ast.NamedType:
+		if _type.Package.Value != "" || len(_type.TypeArgs) > 0 {
+			break
+		}
+		switch _type.Name.Value {
+		case "bool":
+			return "false"
+		case "string":
+			return "\"\""
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128",
+			"byte", "rune":
+			return "0"
+		case "error", "any":
+			return "nil"
+		}
+	case ast.PointerType, ast.SliceType, ast.MapType, ast.ChanType, ast.FuncType, ast.InterfaceType:
+		return "nil"
+	}
+	return fmt.Sprintf("*new(%s)", transpiler.ExprString(typeExpr, "", locals))
+}
+
+// IsErrorType reports whether the given type is the predeclared error type.
+func IsErrorType(typeExpr ast.TypeExpr) bool {
+	namedType, isNamedType := typeExpr.(ast.NamedType)
+	return isNamedType && namedType.Package.Value == "" && namedType.Name.Value == "error"
+}
+
+// ReturnsError reports whether the last of the given result types is error.
+func ReturnsError(returnTypes []ast.TypeExpr) bool {
+	return len(returnTypes) > 0 && IsErrorType(returnTypes[len(returnTypes)-1])
+}
+
+// ParameterTypes returns the type of every parameter or result, one for
+// each name of a group.
+func ParameterTypes(parameters []ast.FuncParameter) []ast.TypeExpr {
+	types := make([]ast.TypeExpr, 0)
+	for _, param := range parameters {
+		if len(param.Names) == 0 {
+			types = append(types, param.Type)
+		}
+		for range param.Names {
+			types = append(types, param.Type)
+		}
+	}
+	return types
+}
+
 func (transpiler *Transpiler) TranspileSymbolExpr(expr ast.SymbolExpr) {
 	transpiler.Write(expr.Symbol.Value)
 }
 
 func (transpiler *Transpiler) TranspileStringExpr(expr ast.StringExpr) {
-	transpiler.Writef("\"%s\"", expr.String.Value)
+	if expr.String.Type == lexer.TokenRawString {
+		transpiler.Writef("`%s`", expr.String.Value)
+	} else {
+		transpiler.Writef("\"%s\"", expr.String.Value)
+	}
 }
 
 func (transpiler *Transpiler) TranspileNumberExpr(expr ast.NumberExpr) {
 	transpiler.Write(expr.Number.Value)
 }
 
-func (transpiler *Transpiler) TranspileAccessExpr(stmtInterface ast.Stmt, expr ast.AccessExpr, indent string, locals map[string]bool) {
-	transpiler.TranspileExpr(stmtInterface, expr.Instance, indent, locals)
-	transpiler.Write(".")
-	transpiler.TranspileExpr(stmtInterface, expr.Field, indent, locals)
+// Operators maps the tokens of the unary, binary and assignment operators to
+// their Go source.
+var Operators = map[lexer.TokenType]string{
+	lexer.TokenPlus:             "+",
+	lexer.TokenMinus:            "-",
+	lexer.TokenStar:             "*",
+	lexer.TokenSlash:            "/",
+	lexer.TokenPercent:          "%",
+	lexer.TokenAmpersand:        "&",
+	lexer.TokenPipe:             "|",
+	lexer.TokenCaret:            "^",
+	lexer.TokenShiftLeft:        "<<",
+	lexer.TokenShiftRight:       ">>",
+	lexer.TokenAndNot:           "&^",
+	lexer.TokenAnd:              "&&",
+	lexer.TokenOr:               "||",
+	lexer.TokenNot:              "!",
+	lexer.TokenEqual:            "==",
+	lexer.TokenNotEqual:         "!=",
+	lexer.TokenLess:             "<",
+	lexer.TokenLessEq:           "<=",
+	lexer.TokenGreater:          ">",
+	lexer.TokenGreaterEq:        ">=",
+	lexer.TokenAssign:           "=",
+	lexer.TokenPlusAssign:       "+=",
+	lexer.TokenMinusAssign:      "-=",
+	lexer.TokenStarAssign:       "*=",
+	lexer.TokenSlashAssign:      "/=",
+	lexer.TokenPercentAssign:    "%=",
+	lexer.TokenAmpersandAssign:  "&=",
+	lexer.TokenPipeAssign:       "|=",
+	lexer.TokenCaretAssign:      "^=",
+	lexer.TokenShiftLeftAssign:  "<<=",
+	lexer.TokenShiftRightAssign: ">>=",
+	lexer.TokenAndNotAssign:     "&^=",
 }
 
-func (transpiler *Transpiler) TranspileBinaryExpr(stmtInterface ast.Stmt, expr ast.BinaryExpr, indent string, locals map[string]bool) {
-	transpiler.TranspileExpr(stmtInterface, expr.Left, indent, locals)
-	switch expr.Operator.Type {
-	case lexer.TokenPlus:
-		transpiler.Write(" + ")
-	case lexer.TokenStar:
-		transpiler.Write(" * ")
+// Operator returns the Go source of the operator token.
+func (transpiler *Transpiler) Operator(token lexer.Token) string {
+	operator, isOperator := Operators[token.Type]
+	if !isOperator {
+		panic(fmt.Sprintf("\n%s... <--- unhandled operator %s", transpiler.String(), token))
+	}
+	return operator
+}
+
+// Precedence returns the precedence of a binary operator in Go, from 5 for
+// the multiplicative operators down to 1 for ||.
+func Precedence(operator lexer.Token) int {
+	switch operator.Type {
+	case lexer.TokenStar, lexer.TokenSlash, lexer.TokenPercent, lexer.TokenShiftLeft, lexer.TokenShiftRight,
+		lexer.TokenAmpersand, lexer.TokenAndNot:
+		return 5
+	case lexer.TokenPlus, lexer.TokenMinus, lexer.TokenPipe, lexer.TokenCaret:
+		return 4
+	case lexer.TokenEqual, lexer.TokenNotEqual, lexer.TokenLess, lexer.TokenLessEq, lexer.TokenGreater, lexer.TokenGreaterEq:
+		return 3
+	case lexer.TokenAnd:
+		return 2
+	case lexer.TokenOr:
+		return 1
 	default:
-		panic(fmt.Sprintf("\n%s... <--- unhandled %s", transpiler.String(), reflect.TypeOf(expr.Operator)))
+		return 0
 	}
-	transpiler.TranspileExpr(stmtInterface, expr.Right, indent, locals)
 }
 
-func (transpiler *Transpiler) TranspileFuncCallExpr(stmtInterface ast.Stmt, expr ast.FuncCallExpr, indent string, locals map[string]bool) {
-	switch stmt := stmtInterface.(type) {
-	case ast.ReturnStmt:
-		if expr.OrPanic {
-			retExists := locals["ret"]
-			if retExists {
-				transpiler.Writef("%sret, err = ", indent)
-			} else {
-				transpiler.Writef("%sret, err := ", indent)
-				locals["ret"] = true
-				locals["err"] = true
+// MayCombine reports whether the operator previous and the operator next
+// would combine into another token if they were not separated by a blank,
+// as in - -x.
+func MayCombine(previous string, next string) bool {
+	switch previous + next[:1] {
+	case "++", "--", "/*", "<-", "<<", "&&", "&^":
+		return true
+	default:
+		return false
+	}
+}
+
+// TranspileUnaryExpr emits a unary expression. An operand that is a binary
+// expression is parenthesized.
+func (transpiler *Transpiler) TranspileUnaryExpr(expr ast.UnaryExpr, indent string, locals *Scope) {
+	operator := transpiler.Operator(expr.Operator)
+	transpiler.Write(operator)
+	switch value := expr.Value.(type) {
+	case ast.BinaryExpr:
+		transpiler.Write("(")
+		transpiler.TranspileBinaryExpr(value, indent, locals)
+		transpiler.Write(")")
+		return
+	case ast.UnaryExpr:
+		if MayCombine(operator, transpiler.Operator(value.Operator)) {
+			transpiler.Write(" ")
+		}
+	}
+	transpiler.TranspileExpr(expr.Value, indent, locals)
+}
+
+// TranspilePrimaryExpr emits the operand of a selector, an index, a slice, a
+// call or a type assertion, in parentheses if it is a unary or a binary
+// expression, as in (*p).x.
+func (transpiler *Transpiler) TranspilePrimaryExpr(expr ast.Expr, indent string, locals *Scope) {
+	switch expr.(type) {
+	case ast.UnaryExpr, ast.BinaryExpr, ast.ReceiveExpr:
+		transpiler.Write("(")
+		transpiler.TranspileExpr(expr, indent, locals)
+		transpiler.Write(")")
+	default:
+		transpiler.TranspileExpr(expr, indent, locals)
+	}
+}
+
+func (transpiler *Transpiler) TranspileAccessExpr(expr ast.AccessExpr, indent string, locals *Scope) {
+	transpiler.TranspilePrimaryExpr(expr.Instance, indent, locals)
+	transpiler.Write(".")
+	transpiler.TranspileExpr(expr.Field, indent, locals)
+}
+
+// TranspileBinaryExpr emits a binary expression with blanks around its
+// operator, and parentheses around the operands that would otherwise group
+// differently.
+func (transpiler *Transpiler) TranspileBinaryExpr(expr ast.BinaryExpr, indent string, locals *Scope) {
+	precedence := Precedence(expr.Operator)
+	transpiler.TranspileOperand(expr.Left, precedence, indent, locals)
+	transpiler.Writef(" %s ", transpiler.Operator(expr.Operator))
+	// binary operators associate to the left, so a right operand of the
+	// same precedence is parenthesized
+	transpiler.TranspileOperand(expr.Right, precedence+1, indent, locals)
+}
+
+// TranspileOperand emits an operand of a binary expression, in parentheses
+// if it is a binary expression of a precedence lower than precedence.
+func (transpiler *Transpiler) TranspileOperand(operand ast.Expr, precedence int, indent string, locals *Scope) {
+	if binaryExpr, isBinaryExpr := operand.(ast.BinaryExpr); isBinaryExpr && Precedence(binaryExpr.Operator) < precedence {
+		transpiler.Write("(")
+		transpiler.TranspileBinaryExpr(binaryExpr, indent, locals)
+		transpiler.Write(")")
+		return
+	}
+	transpiler.TranspileExpr(operand, indent, locals)
+}
+
+func (transpiler *Transpiler) TranspileFuncCallExpr(expr ast.FuncCallExpr, indent string, locals *Scope) {
+	if _, isCheckedCall := IsCheckedCall(expr); isCheckedCall && !transpiler.IgnoreChecks {
+		panic(fmt.Sprintf("\n%s... <--- unhandled nested %s", transpiler.String(), reflect.TypeOf(expr)))
+	}
+	transpiler.TranspilePrimaryExpr(expr.Func, indent, locals)
+	transpiler.Write("(")
+	inHeader := transpiler.InHeader
+	transpiler.InHeader = false
+	transpiler.TranspileExpr(expr.Args, indent, locals)
+	transpiler.InHeader = inHeader
+	if expr.Ellipsis {
+		transpiler.Write("...")
+	}
+	transpiler.Write(")")
+}
+
+func (transpiler *Transpiler) TranspileIndexExpr(expr ast.IndexExpr, indent string, locals *Scope) {
+	if expr.OrPanic && !transpiler.IgnoreChecks {
+		panic(fmt.Sprintf("\n%s... <--- unhandled nested %s", transpiler.String(), reflect.TypeOf(expr)))
+	}
+	transpiler.TranspilePrimaryExpr(expr.Value, indent, locals)
+	transpiler.Write("[")
+	inHeader := transpiler.InHeader
+	transpiler.InHeader = false
+	transpiler.TranspileExpr(expr.Index, indent, locals)
+	transpiler.InHeader = inHeader
+	transpiler.Write("]")
+}
+
+// TranspileSliceExpr emits a slice expression. Like gofmt, it puts spaces
+// around the colons between the indices if there is more than one index and
+// one of them is a binary expression.
+func (transpiler *Transpiler) TranspileSliceExpr(expr ast.SliceExpr, indent string, locals *Scope) {
+	transpiler.TranspilePrimaryExpr(expr.Value, indent, locals)
+	transpiler.Write("[")
+	inHeader := transpiler.InHeader
+	transpiler.InHeader = false
+	indices := []ast.Expr{expr.Low, expr.High}
+	if expr.Slice3 {
+		indices = append(indices, expr.Max)
+	}
+	indexCount := 0
+	hasBinaryExpr := false
+	for _, index := range indices {
+		if index != nil {
+			indexCount += 1
+		}
+		if _, isBinaryExpr := index.(ast.BinaryExpr); isBinaryExpr {
+			hasBinaryExpr = true
+		}
+	}
+	blanks := indexCount > 1 && hasBinaryExpr
+	for i, index := range indices {
+		if i > 0 {
+			if blanks && indices[i-1] != nil {
+				transpiler.Write(" ")
+			}
+			transpiler.Write(":")
+			if blanks && index != nil {
+				transpiler.Write(" ")
 			}
-			transpiler.TranspileExpr(stmtInterface, expr.Func, indent, locals)
-			transpiler.Write("(")
-			transpiler.TranspileExpr(stmtInterface, expr.Args, indent, locals)
-			transpiler.Write(")\n")
-			transpiler.Writef("%sif err != nil {\n", indent)
-			transpiler.Writef("%s%spanic(err)\n", indent, indent)
-			transpiler.Writef("%s}\n", indent)
-			transpiler.Writef("%sreturn ret", indent)
-		} else {
-			transpiler.TranspileExpr(stmtInterface, expr.Func, indent, locals)
-			transpiler.Write("(")
-			transpiler.TranspileExpr(stmtInterface, expr.Args, indent, locals)
-			transpiler.Write(")")
 		}
-	case ast.ExprStmt:
-		switch stmt.Expr.(type) {
-		case ast.DeclAssignExpr:
-			transpiler.TranspileExpr(stmtInterface, expr.Func, indent, locals)
-			transpiler.Write("(")
-			transpiler.TranspileExpr(stmtInterface, expr.Args, indent, locals)
-			transpiler.Write(")\n")
-			if expr.OrPanic {
-				transpiler.Writef("%sif err != nil { \n", indent)
-				transpiler.Writef("%s%spanic(err)\n", indent, indent)
-				transpiler.Writef("%s}\n", indent)
+		transpiler.TranspileExpr(index, indent, locals)
+	}
+	transpiler.InHeader = inHeader
+	transpiler.Write("]")
+}
+
+func (transpiler *Transpiler) TranspileTypeAssertExpr(expr ast.TypeAssertExpr, indent string, locals *Scope) {
+	if expr.OrPanic && !transpiler.IgnoreChecks {
+		panic(fmt.Sprintf("\n%s... <--- unhandled nested %s", transpiler.String(), reflect.TypeOf(expr)))
+	}
+	transpiler.TranspilePrimaryExpr(expr.Value, indent, locals)
+	transpiler.Write(".(")
+	if expr.Type == nil {
+		transpiler.Write("type")
+	} else {
+		transpiler.TranspileExpr(expr.Type, indent, locals)
+	}
+	transpiler.Write(")")
+}
+
+func (transpiler *Transpiler) TranspileReceiveExpr(expr ast.ReceiveExpr, indent string, locals *Scope) {
+	if expr.OrPanic && !transpiler.IgnoreChecks {
+		panic(fmt.Sprintf("\n%s... <--- unhandled nested %s", transpiler.String(), reflect.TypeOf(expr)))
+	}
+	transpiler.Write("<-")
+	transpiler.TranspileExpr(expr.Channel, indent, locals)
+}
+
+func (transpiler *Transpiler) TranspileCompositeLitExpr(expr ast.CompositeLitExpr, indent string, locals *Scope) {
+	if !transpiler.InHeader {
+		transpiler.TranspileCompositeLit(expr, indent, locals)
+		return
+	}
+	transpiler.InHeader = false
+	switch expr.Type.(type) {
+	case ast.SymbolExpr, ast.AccessExpr, ast.IndexExpr:
+		// the brace would start the block of the statement
+		transpiler.Write("(")
+		transpiler.TranspileCompositeLit(expr, indent, locals)
+		transpiler.Write(")")
+	default:
+		transpiler.TranspileCompositeLit(expr, indent, locals)
+	}
+	transpiler.InHeader = true
+}
+
+// TranspileFuncLitExpr emits a function literal. Its body gets a scope of its
+// own, and the checked calls in it return the results of the literal.
+func (transpiler *Transpiler) TranspileFuncLitExpr(expr ast.FuncLitExpr, indent string, locals *Scope) {
+	transpiler.Write("func")
+	transpiler.TranspileSignature(expr.Type.Parameters, expr.Type.Results, indent, locals)
+	if transpiler.IgnoreChecks {
+		// the body is elided where the source is shown in an error
+		transpiler.Write(" {...}")
+		return
+	}
+	transpiler.Write(" {\n")
+	innerLocals := NewFuncScope(locals)
+	transpiler.DeclareParameters(expr.Type.Parameters, indent, innerLocals)
+	transpiler.DeclareParameters(expr.Type.Results, indent, innerLocals)
+	outerReturnTypes := transpiler.ReturnTypes
+	transpiler.ReturnTypes = ParameterTypes(expr.Type.Results)
+	inHeader := transpiler.InHeader
+	transpiler.InHeader = false
+	transpiler.TranspileWithDepth(expr.Block.(ast.BlockStmt).Body, len(indent)+1, innerLocals)
+	transpiler.InHeader = inHeader
+	transpiler.ReturnTypes = outerReturnTypes
+	transpiler.Writef("%s}", indent)
+}
+
+// TranspileCompositeLit emits a composite literal. The elements of a
+// multi-line literal are put on lines of their own with their values
+// aligned the way gofmt does.
+func (transpiler *Transpiler) TranspileCompositeLit(expr ast.CompositeLitExpr, indent string, locals *Scope) {
+	transpiler.TranspileExpr(expr.Type, indent, locals)
+	if !expr.MultiLine {
+		transpiler.Write("{")
+		for i, element := range expr.Elements {
+			if i > 0 {
+				transpiler.Write(", ")
 			}
-		case ast.FuncCallExpr:
-			transpiler.Write(indent)
-			if expr.OrPanic {
-				errExists := locals["err"]
-				if errExists {
-					transpiler.Write("err = ")
-				} else {
-					transpiler.Write("err := ")
-					locals["err"] = true
-				}
+			if element.Key != nil {
+				transpiler.TranspileExpr(element.Key, indent, locals)
+				transpiler.Write(": ")
 			}
-			transpiler.TranspileExpr(stmtInterface, expr.Func, indent, locals)
-			transpiler.Write("(")
-			transpiler.TranspileExpr(stmtInterface, expr.Args, indent, locals)
-			transpiler.Write(")\n")
-			if expr.OrPanic {
-				transpiler.Writef("%sif err != nil { \n", indent)
-				transpiler.Writef("%s%spanic(err)\n", indent, indent)
-				transpiler.Writef("%s}\n", indent)
+			transpiler.TranspileExpr(element.Value, indent, locals)
+		}
+		transpiler.Write("}")
+		return
+	}
+	transpiler.Write("{\n")
+	elementIndent := indent + "\t"
+	lines := make([]string, 0)
+	for _, element := range expr.Elements {
+		value := transpiler.ExprString(element.Value, elementIndent, locals)
+		key := ""
+		if element.Key != nil {
+			key = transpiler.ExprString(element.Key, elementIndent, locals)
+		}
+		if strings.Contains(key+value, "\n") {
+			// a multi-line element ends the columns, like gofmt does
+			transpiler.WriteColumns(lines, elementIndent)
+			lines = lines[:0]
+			transpiler.Write(elementIndent)
+			if element.Key != nil {
+				transpiler.Writef("%s: ", key)
 			}
-		default:
-			panic(fmt.Sprintf("\n%s... <--- unhandled %s", transpiler.String(), reflect.TypeOf(expr)))
+			transpiler.Writef("%s,\n", value)
+		} else if element.Key != nil {
+			lines = append(lines, fmt.Sprintf("%s:\t%s,", key, value))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s,", value))
 		}
+	}
+	transpiler.WriteColumns(lines, elementIndent)
+	transpiler.Writef("%s}", indent)
+}
+
+// TranspileCheckedCall emits the call, assigning its results to targets
+// followed by the error, and the error check that handles a non-nil error.
+// If declare is set, the targets are declared by the assignment.
+// Checked calls nested in the call are hoisted before it.
+func (transpiler *Transpiler) TranspileCheckedCall(targets []string, declare bool, expr ast.FuncCallExpr, indent string, locals *Scope) {
+	transpiler.TranspileHoistedCheckedCall(targets, declare, transpiler.HoistCheckedCallArgs(expr, indent, locals), expr, indent, locals)
+}
+
+// HoistCheckedCallArgs hoists the checked calls nested in the function and
+// the arguments of a checked call.
+func (transpiler *Transpiler) HoistCheckedCallArgs(expr ast.FuncCallExpr, indent string, locals *Scope) ast.FuncCallExpr {
+	operands := transpiler.HoistOperands(append([]ast.Expr{expr.Func}, ListValues(expr.Args)...), indent, locals)
+	expr.Func = operands[0]
+	expr.Args = NewListExpr(operands[1:])
+	return expr
+}
+
+// TranspileHoistedCheckedCall is TranspileCheckedCall for a call whose nested
+// checked calls have already been hoisted. The original call is used to
+// report the source of the call on failure.
+func (transpiler *Transpiler) TranspileHoistedCheckedCall(targets []string, declare bool, expr ast.FuncCallExpr, original ast.FuncCallExpr, indent string, locals *Scope) {
+	err, declareErr := transpiler.ReusableName(locals, "err", "error", "Err")
+	transpiler.TranspileCheckAssign(targets, declare, err, "error", declareErr, indent, locals)
+	transpiler.TranspilePrimaryExpr(expr.Func, indent, locals)
+	transpiler.Write("(")
+	transpiler.TranspileExpr(expr.Args, indent, locals)
+	transpiler.Write(")\n")
+	innerLocals := NewScope(locals)
+	errName := original.OrElseErr.Value
+	if original.OrElseBlock != nil && errName != "" && errName != err {
+		innerLocals.Declare(errName, "error")
+		transpiler.Writef("%sif %s := %s; %s != nil {\n", indent, errName, err, errName)
+	} else {
+		transpiler.Writef("%sif %s != nil {\n", indent, err)
+	}
+	transpiler.TranspileErrorAction(targets, original, err, indent+"\t", innerLocals)
+	transpiler.Writef("%s}\n", indent)
+}
+
+// TranspileChecked emits a checked call or a comma-ok expression assigned to
+// targets. If declare is set, the targets are declared by the assignment.
+func (transpiler *Transpiler) TranspileChecked(targets []string, declare bool, expr ast.Expr, indent string, locals *Scope) {
+	if funcCallExpr, isCheckedCall := IsCheckedCall(expr); isCheckedCall {
+		transpiler.TranspileCheckedCall(targets, declare, funcCallExpr, indent, locals)
+	} else {
+		transpiler.TranspileCommaOk(targets, declare, expr, indent, locals)
+	}
+}
+
+// TranspileCommaOk emits the comma-ok form of expr, assigning its value to
+// targets followed by ok, and the check that handles a false ok.
+func (transpiler *Transpiler) TranspileCommaOk(targets []string, declare bool, expr ast.Expr, indent string, locals *Scope) {
+	unchecked, token, reason, _ := CommaOk(expr)
+	hoisted := transpiler.HoistCheckedCalls(unchecked, indent, locals)
+	ok, declareOk := transpiler.ReusableName(locals, "ok", "bool", "Ok")
+	transpiler.TranspileCheckAssign(targets, declare, ok, "bool", declareOk, indent, locals)
+	transpiler.TranspileExpr(hoisted, indent, locals)
+	transpiler.Write("\n")
+	transpiler.Writef("%sif !%s {\n", indent, ok)
+	transpiler.RequireImport("errors")
+	transpiler.IgnoreChecks = true
+	message := fmt.Sprintf("%s: %s: %s", transpiler.Location(token), transpiler.ExprString(unchecked, "", locals), reason)
+	transpiler.IgnoreChecks = false
+	transpiler.TranspileFailure(fmt.Sprintf("errors.New(%s)", strconv.Quote(message)), indent+"\t")
+	transpiler.Writef("%s}\n", indent)
+}
+
+// ReusableName returns the variable of the given type that receives the
+// error of a checked call, or the ok of a comma-ok expression, in the given
+// scope and whether it has to be declared. A variable generated for an
+// enclosing scope is reused unless it is shadowed. Variables of the user,
+// including parameters and named results, are never reused, so name is
+// only used if it does not shadow or clash with one of them.
+func (transpiler *Transpiler) ReusableName(locals *Scope, name string, _type string, kind string) (string, bool) {
+	if reusable, exists := locals.LookupReusable(_type); exists {
+		return reusable, false
+	}
+	reusable := transpiler.TempName(locals, name, kind)
+	locals.Declare(reusable, _type)
+	locals.Reusable[_type] = reusable
+	return reusable, true
+}
+
+// TranspileCheckAssign emits the left side of the assignment of a checked
+// expression to targets followed by the variable name of the given type.
+// If declare is set, the targets are declared by the assignment.
+func (transpiler *Transpiler) TranspileCheckAssign(targets []string, declare bool, name string, _type string, declareName bool, indent string, locals *Scope) {
+	operator := "="
+	if declare {
+		operator = ":="
+		for _, target := range targets {
+			locals.Declare(target, "")
+		}
+	} else if declareName {
+		blanks := true
+		for _, target := range targets {
+			blanks = blanks && target == "_"
+		}
+		if blanks {
+			operator = ":="
+		} else {
+			transpiler.Writef("%svar %s %s\n", indent, name, _type)
+		}
+	}
+	transpiler.Writef("%s%s %s ", indent, strings.Join(append(targets[:len(targets):len(targets)], name), ", "), operator)
+}
+
+// TempName returns name if generated code can declare it in the given scope,
+// otherwise a fresh name such as __goxErr1.
+func (transpiler *Transpiler) TempName(locals *Scope, name string, kind string) string {
+	for !locals.IsFree(name) {
+		transpiler.Temps += 1
+		name = fmt.Sprintf("__gox%s%d", kind, transpiler.Temps)
+	}
+	return name
+}
+
+// TranspileErrorAction emits what happens to the non-nil error err,
+// depending on the error handling keyword of the call.
+func (transpiler *Transpiler) TranspileErrorAction(targets []string, expr ast.FuncCallExpr, err string, indent string, locals *Scope) {
+	switch {
+	case len(expr.Catches) > 0:
+		transpiler.TranspileCatchClauses(targets, expr, err, indent, locals)
+	case expr.OrPanic:
+		transpiler.TranspileFailure(transpiler.LocatedError(expr, err, locals), indent)
+	case expr.OrReturn:
+		transpiler.TranspileErrorReturn(err, indent, locals)
+	case expr.OrWrap != nil:
+		transpiler.RequireImport("fmt")
+		message := strings.ReplaceAll(expr.OrWrap.(ast.StringExpr).String.Value, "%", "%%")
+		transpiler.TranspileErrorReturn(fmt.Sprintf("fmt.Errorf(\"%s: %%w\", %s)", message, err), indent, locals)
+	case expr.OrElse != nil:
+		if len(targets) == 0 {
+			panic(fmt.Sprintf("\n%s... <--- or_else value requires a call result to replace", transpiler.String()))
+		}
+		// a fallback call may return a value for every result, any other
+		// expression is a single value
+		if _, isFuncCallExpr := expr.OrElse.(ast.FuncCallExpr); !isFuncCallExpr && len(targets) != 1 {
+			panic(fmt.Sprintf("\n%s... <--- assignment mismatch: %d variables but or_else gives 1 value", transpiler.String(), len(targets)))
+		}
+		transpiler.Writef("%s%s = ", indent, strings.Join(targets, ", "))
+		transpiler.TranspileExpr(expr.OrElse, indent, locals)
+		transpiler.Write("\n")
+	case expr.OrElseBlock != nil:
+		transpiler.TranspileWithDepth(expr.OrElseBlock.(ast.BlockStmt).Body, len(indent), locals)
 	default:
-		panic(fmt.Sprintf("\n%s... <--- unhandled %s", transpiler.String(), reflect.TypeOf(stmt)))
+		panic(fmt.Sprintf("\n%s... <--- unhandled %s", transpiler.String(), reflect.TypeOf(expr)))
 	}
 }
 
-func (transpiler *Transpiler) TranspileDeclAssignExpr(stmtInterface ast.Stmt, expr ast.DeclAssignExpr, indent string, locals map[string]bool) {
+// TranspileCatchClauses emits the catch clauses as a chain of errors.Is and
+// errors.As checks. If no clause matches, the error is handled by the
+// remaining error handling keyword of the call. Without one, the error is
+// returned if the enclosing function returns an error, otherwise it panics.
+func (transpiler *Transpiler) TranspileCatchClauses(targets []string, expr ast.FuncCallExpr, err string, indent string, locals *Scope) {
+	transpiler.RequireImport("errors")
 	transpiler.Write(indent)
-	transpiler.TranspileExpr(stmtInterface, expr.Left, indent, locals)
-	funcCallExpr, isFuncCallExpr := expr.Right.(ast.FuncCallExpr)
-	if isFuncCallExpr && funcCallExpr.OrPanic {
-		transpiler.Write(", err")
-		locals["err"] = true
+	for _, catchClause := range expr.Catches {
+		innerLocals := NewScope(locals)
+		if catchClause.Value != nil {
+			transpiler.Writef("if errors.Is(%s, ", err)
+			transpiler.TranspileExpr(catchClause.Value, indent, locals)
+			transpiler.Write(") {\n")
+		} else {
+			name := catchClause.Name.Value
+			_type := transpiler.ExprString(catchClause.Type, indent, locals)
+			innerLocals.Declare(name, _type)
+			if _, isPointer := catchClause.Type.(ast.UnaryExpr); isPointer {
+				transpiler.Writef("if %s := (%s)(nil); errors.As(%s, &%s) {\n", name, _type, err, name)
+			} else {
+				transpiler.Writef("if %s := *new(%s); errors.As(%s, &%s) {\n", name, _type, err, name)
+			}
+		}
+		transpiler.TranspileWithDepth(catchClause.Block.(ast.BlockStmt).Body, len(indent)+1, innerLocals)
+		transpiler.Writef("%s} else ", indent)
 	}
+	transpiler.Write("{\n")
+	fallback := expr
+	fallback.Catches = nil
+	if _, isCheckedCall := IsCheckedCall(fallback); !isCheckedCall {
+		if ReturnsError(transpiler.ReturnTypes) {
+			fallback.OrReturn = true
+		} else {
+			fallback.OrPanic = true
+		}
+	}
+	transpiler.TranspileErrorAction(targets, fallback, err, indent+"\t", locals)
+	transpiler.Writef("%s}\n", indent)
+}
+
+// Location returns the location of the token in the .gox file.
+func (transpiler *Transpiler) Location(token lexer.Token) string {
+	if transpiler.FileName == "" {
+		return fmt.Sprintf("line %d", token.Line)
+	}
+	return fmt.Sprintf("%s:%d", transpiler.FileName, token.Line)
+}
+
+// LocatedError returns an expression that wraps err with the location and
+// the source of the call in the .gox file, for example
+// fmt.Errorf("greeting.gox:17: greetingForLang(\"Australian\"): %w", err)
+func (transpiler *Transpiler) LocatedError(expr ast.FuncCallExpr, err string, locals *Scope) string {
+	transpiler.RequireImport("fmt")
+	location := transpiler.Location(expr.ParenOpen)
+	call := ast.FuncCallExpr{
+		Func:     expr.Func,
+		Args:     expr.Args,
+		Ellipsis: expr.Ellipsis,
+	}
+	// nested checked calls are shown as they appear in the source
+	transpiler.IgnoreChecks = true
+	format := fmt.Sprintf("%s: %s: ", location, transpiler.ExprString(call, "", locals))
+	transpiler.IgnoreChecks = false
+	format = strings.ReplaceAll(format, "%", "%%") + "%w"
+	return fmt.Sprintf("fmt.Errorf(%s, %s)", strconv.Quote(format), err)
+}
+
+// TranspileFailure emits the or_panic failure strategy for the given error.
+func (transpiler *Transpiler) TranspileFailure(err string, indent string) {
+	switch transpiler.OnError {
+	case OnErrorPanic:
+		transpiler.Writef("%spanic(%s)\n", indent, err)
+	case OnErrorLogFatal:
+		transpiler.RequireImport("log")
+		transpiler.Writef("%slog.Fatal(%s)\n", indent, err)
+	case OnErrorOsExit:
+		transpiler.RequireImport("fmt")
+		transpiler.RequireImport("os")
+		transpiler.Writef("%sfmt.Fprintln(os.Stderr, %s)\n", indent, err)
+		transpiler.Writef("%sos.Exit(1)\n", indent)
+	default:
+		transpiler.Writef("%s%s(%s)\n", indent, transpiler.OnError, err)
+	}
+}
+
+// TranspileErrorReturn emits a return statement that returns the zero values
+// of the enclosing function together with the given error.
+func (transpiler *Transpiler) TranspileErrorReturn(err string, indent string, locals *Scope) {
+	if !ReturnsError(transpiler.ReturnTypes) {
+		panic(fmt.Sprintf("\n%s... <--- cannot return err from a function without error result", transpiler.String()))
+	}
+	values := make([]string, 0)
+	for _, _type := range transpiler.ReturnTypes[:len(transpiler.ReturnTypes)-1] {
+		values = append(values, transpiler.ZeroValue(_type, locals))
+	}
+	values = append(values, err)
+	transpiler.Writef("%sreturn %s\n", indent, strings.Join(values, ", "))
+}
+
+func (transpiler *Transpiler) TranspileDeclAssignExpr(expr ast.DeclAssignExpr, indent string, locals *Scope) {
+	targets := ListValues(expr.Left)
+	values := ListValues(expr.Right)
+	for i, value := range targets {
+		if symbolExpr, isSymbolExpr := value.(ast.SymbolExpr); isSymbolExpr {
+			_type := ""
+			if len(values) == len(targets) {
+				_type = transpiler.LiteralType(values[i], locals)
+			}
+			locals.Declare(symbolExpr.Symbol.Value, _type)
+		}
+	}
+	transpiler.TranspileExpr(expr.Left, indent, locals)
 	transpiler.Write(" := ")
-	transpiler.TranspileExpr(stmtInterface, expr.Right, indent, locals)
+	transpiler.TranspileExpr(expr.Right, indent, locals)
 }
 
-func (transpiler *Transpiler) TranspileAssignmentExpr(stmtInterface ast.Stmt, expr ast.AssignmentExpr, indent string, locals map[string]bool) {
-	transpiler.Write(indent)
-	transpiler.TranspileExpr(stmtInterface, expr.Left, indent, locals)
-	transpiler.Write(" = ")
-	transpiler.TranspileExpr(stmtInterface, expr.Right, indent, locals)
-	transpiler.Write("\n")
+func (transpiler *Transpiler) TranspileAssignmentExpr(expr ast.AssignmentExpr, indent string, locals *Scope) {
+	transpiler.TranspileExpr(expr.Left, indent, locals)
+	transpiler.Writef(" %s ", transpiler.Operator(expr.Operator))
+	transpiler.TranspileExpr(expr.Right, indent, locals)
 }
 
-func (transpiler *Transpiler) TranspileListExpr(stmtInterface ast.Stmt, expr ast.ListExpr, indent string, locals map[string]bool) {
-	transpiler.TranspileExpr(stmtInterface, expr.Value, indent, locals)
+// ListValues flattens a list expression into its values.
+func ListValues(expr ast.Expr) []ast.Expr {
+	listExpr, isListExpr := expr.(ast.ListExpr)
+	if !isListExpr {
+		if expr == nil {
+			return []ast.Expr{}
+		}
+		return []ast.Expr{expr}
+	}
+	return append(ListValues(listExpr.Value), ListValues(listExpr.Next)...)
+}
+
+func (transpiler *Transpiler) TranspileSendExpr(expr ast.SendExpr, indent string, locals *Scope) {
+	transpiler.TranspileExpr(expr.Channel, indent, locals)
+	transpiler.Write(" <- ")
+	transpiler.TranspileExpr(expr.Value, indent, locals)
+}
+
+func (transpiler *Transpiler) TranspileListExpr(expr ast.ListExpr, indent string, locals *Scope) {
+	transpiler.TranspileExpr(expr.Value, indent, locals)
 	if expr.Next != nil {
 		transpiler.Write(", ")
-		transpiler.TranspileExpr(stmtInterface, expr.Next, "", locals)
+		transpiler.TranspileExpr(expr.Next, indent, locals)
+	}
+}
+
+// ExprString returns the Go source of expr without writing it to the output.
+func (transpiler *Transpiler) ExprString(expr ast.Expr, indent string, locals *Scope) string {
+	output := transpiler.StringBuilder
+	transpiler.StringBuilder = strings.Builder{}
+	transpiler.TranspileExpr(expr, indent, locals)
+	str := transpiler.StringBuilder.String()
+	transpiler.StringBuilder = output
+	return str
+}
+
+func (transpiler *Transpiler) ExprStrings(exprs []ast.Expr, indent string, locals *Scope) []string {
+	strs := make([]string, 0)
+	for _, expr := range exprs {
+		strs = append(strs, transpiler.ExprString(expr, indent, locals))
 	}
+	return strs
 }
 
-func (transpiler *Transpiler) TranspileExpr(stmtInterface ast.Stmt, exprInterface ast.Expr, indent string, locals map[string]bool) {
+func (transpiler *Transpiler) TranspileExpr(exprInterface ast.Expr, indent string, locals *Scope) {
 	switch expr := exprInterface.(type) {
 	case nil:
 		return
@@ -175,103 +936,1078 @@ func (transpiler *Transpiler) TranspileExpr(stmtInterface ast.Stmt, exprInterfac
 		transpiler.TranspileStringExpr(expr)
 	case ast.NumberExpr:
 		transpiler.TranspileNumberExpr(expr)
+	case ast.UnaryExpr:
+		transpiler.TranspileUnaryExpr(expr, indent, locals)
 	case ast.AccessExpr:
-		transpiler.TranspileAccessExpr(stmtInterface, expr, indent, locals)
+		transpiler.TranspileAccessExpr(expr, indent, locals)
 	case ast.BinaryExpr:
-		transpiler.TranspileBinaryExpr(stmtInterface, expr, indent, locals)
+		transpiler.TranspileBinaryExpr(expr, indent, locals)
 	case ast.FuncCallExpr:
-		transpiler.TranspileFuncCallExpr(stmtInterface, expr, indent, locals)
+		transpiler.TranspileFuncCallExpr(expr, indent, locals)
+	case ast.IndexExpr:
+		transpiler.TranspileIndexExpr(expr, indent, locals)
+	case ast.SliceExpr:
+		transpiler.TranspileSliceExpr(expr, indent, locals)
+	case ast.TypeAssertExpr:
+		transpiler.TranspileTypeAssertExpr(expr, indent, locals)
+	case ast.ReceiveExpr:
+		transpiler.TranspileReceiveExpr(expr, indent, locals)
 	case ast.AssignmentExpr:
-		transpiler.TranspileAssignmentExpr(stmtInterface, expr, indent, locals)
+		transpiler.TranspileAssignmentExpr(expr, indent, locals)
 	case ast.DeclAssignExpr:
-		transpiler.TranspileDeclAssignExpr(stmtInterface, expr, indent, locals)
+		transpiler.TranspileDeclAssignExpr(expr, indent, locals)
+	case ast.SendExpr:
+		transpiler.TranspileSendExpr(expr, indent, locals)
 	case ast.ListExpr:
-		transpiler.TranspileListExpr(stmtInterface, expr, indent, locals)
+		transpiler.TranspileListExpr(expr, indent, locals)
+	case ast.CompositeLitExpr:
+		transpiler.TranspileCompositeLitExpr(expr, indent, locals)
+	case ast.FuncLitExpr:
+		transpiler.TranspileFuncLitExpr(expr, indent, locals)
+	case ast.TypeExpr:
+		transpiler.TranspileTypeExpr(expr, indent, locals)
 	default:
 		panic(fmt.Sprintf("\n%s... <--- unhandled %s", transpiler.StringBuilder.String(), reflect.TypeOf(expr)))
 	}
 }
 
+// ApplyDirective applies a //gox: directive to the whole file.
+func (transpiler *Transpiler) ApplyDirective(stmt ast.DirectiveStmt) {
+	fields := strings.Fields(stmt.Directive.Value)
+	if len(fields) == 2 && fields[0] == "onerror" {
+		transpiler.OnError = fields[1]
+		return
+	}
+	panic(fmt.Sprintf("invalid directive //gox:%s at line %d", stmt.Directive.Value, stmt.Directive.Line))
+}
+
 func (transpiler *Transpiler) TranspilePackageStmt(stmt ast.PackageStmt, indent string) {
 	transpiler.Writef("%spackage %s\n\n", indent, stmt.PackageName.Value)
+	transpiler.ImportsPos = transpiler.StringBuilder.Len()
 }
 
-func (transpiler *Transpiler) TranspileImportStmt(stmt ast.ImportStmt, indent string) {
-	transpiler.Writef("%simport ", indent)
+// TranspileImportStmt only collects the imported packages. They are written
+// by TranspileImports once the whole file has been transpiled, so that
+// packages required by the generated code can be added.
+func (transpiler *Transpiler) TranspileImportStmt(stmt ast.ImportStmt, locals *Scope) {
 	if len(stmt.PackageNames) == 0 {
 		panic(fmt.Sprintf("\n%s... <--- ", transpiler.StringBuilder.String()))
-	} else if len(stmt.PackageNames) == 1 {
-		transpiler.Writef("\"%s\"\n\n", stmt.PackageNames[0].Value)
+	}
+	for _, packageName := range stmt.PackageNames {
+		transpiler.RequireImport(packageName.Value)
+		locals.Declare(path.Base(packageName.Value), "")
+	}
+	transpiler.ImportsPos = transpiler.StringBuilder.Len()
+}
+
+// RequireImport adds the package to the imports unless already imported.
+func (transpiler *Transpiler) RequireImport(packageName string) {
+	for _, _import := range transpiler.Imports {
+		if _import == packageName {
+			return
+		}
+	}
+	transpiler.Imports = append(transpiler.Imports, packageName)
+}
+
+func (transpiler *Transpiler) TranspileImports() {
+	if len(transpiler.Imports) == 0 {
+		return
+	}
+	imports := strings.Builder{}
+	if len(transpiler.Imports) == 1 {
+		imports.WriteString(fmt.Sprintf("import \"%s\"\n\n", transpiler.Imports[0]))
+	} else {
+		imports.WriteString("import (\n")
+		for _, packageName := range transpiler.Imports {
+			imports.WriteString(fmt.Sprintf("\t\"%s\"\n", packageName))
+		}
+		imports.WriteString(")\n\n")
+	}
+	output := transpiler.StringBuilder.String()
+	transpiler.StringBuilder.Reset()
+	transpiler.Write(output[:transpiler.ImportsPos])
+	transpiler.Write(imports.String())
+	transpiler.Write(output[transpiler.ImportsPos:])
+}
+
+func (transpiler *Transpiler) TranspileFuncDeclStmt(stmt ast.FuncDeclStmt, indent string, depth int, locals *Scope) {
+	innerLocals := NewFuncScope(locals)
+	transpiler.Writef("%sfunc ", indent)
+	if stmt.Receiver != nil {
+		// methods are not in the scope of the package
+		receiver := []ast.FuncParameter{*stmt.Receiver}
+		transpiler.DeclareParameters(receiver, indent, innerLocals)
+		transpiler.Writef("(%s) ", transpiler.ParameterList(receiver, indent, locals))
 	} else {
+		locals.Declare(stmt.Name.Value, "")
+	}
+	transpiler.Write(stmt.Name.Value)
+	transpiler.TranspileTypeParams(stmt.TypeParams, indent, innerLocals)
+	transpiler.DeclareParameters(stmt.Parameters, indent, innerLocals)
+	transpiler.DeclareParameters(stmt.Results, indent, innerLocals)
+	transpiler.TranspileSignature(stmt.Parameters, stmt.Results, indent, locals)
+	transpiler.Write(" {\n")
+	outerReturnTypes := transpiler.ReturnTypes
+	transpiler.ReturnTypes = ParameterTypes(stmt.Results)
+	transpiler.TranspileWithDepth(stmt.Block.(ast.BlockStmt).Body, depth+1, innerLocals)
+	transpiler.ReturnTypes = outerReturnTypes
+	transpiler.Write("}\n\n")
+}
+
+// TranspileSignature emits the parameters and the results of a function or
+// method.
+func (transpiler *Transpiler) TranspileSignature(parameters []ast.FuncParameter, results []ast.FuncParameter, indent string, locals *Scope) {
+	transpiler.Writef("(%s)", transpiler.ParameterList(parameters, indent, locals))
+	if len(results) == 1 && len(results[0].Names) == 0 {
+		transpiler.Writef(" %s", transpiler.ExprString(results[0].Type, indent, locals))
+	} else if len(results) > 0 {
+		transpiler.Writef(" (%s)", transpiler.ParameterList(results, indent, locals))
+	}
+}
+
+// ParameterList returns the comma separated parameters or results of a
+// function without the parentheses.
+func (transpiler *Transpiler) ParameterList(parameters []ast.FuncParameter, indent string, locals *Scope) string {
+	params := make([]string, 0)
+	for _, param := range parameters {
+		_type := transpiler.ExprString(param.Type, indent, locals)
+		if param.Variadic {
+			_type = "..." + _type
+		}
+		if len(param.Names) == 0 {
+			params = append(params, _type)
+			continue
+		}
+		names := make([]string, 0)
+		for _, name := range param.Names {
+			names = append(names, name.Value)
+		}
+		params = append(params, fmt.Sprintf("%s %s", strings.Join(names, ", "), _type))
+	}
+	return strings.Join(params, ", ")
+}
+
+// DeclareParameters declares the named parameters or results of a function
+// in the scope of its body.
+func (transpiler *Transpiler) DeclareParameters(parameters []ast.FuncParameter, indent string, locals *Scope) {
+	for _, param := range parameters {
+		_type := transpiler.ExprString(param.Type, indent, locals)
+		if param.Variadic {
+			_type = "[]" + _type
+		}
+		for _, name := range param.Names {
+			locals.Declare(name.Value, _type)
+		}
+	}
+}
+
+// ReceiverTypeName returns the name of the type of a receiver, without the
+// pointer and the type parameters, such as Server for *Server.
+func ReceiverTypeName(typeExpr ast.TypeExpr) string {
+	switch _type := typeExpr.(type) {
+	case ast.PointerType:
+		return ReceiverTypeName(_type.Elem)
+	case ast.NamedType:
+		return _type.Name.Value
+	default:
+		return ""
+	}
+}
+
+// LiteralType returns the type of expr if it is a composite literal or the
+// address of one, such as *Server for &Server{}, or "" otherwise.
+func (transpiler *Transpiler) LiteralType(exprInterface ast.Expr, locals *Scope) string {
+	switch expr := exprInterface.(type) {
+	case ast.CompositeLitExpr:
+		if expr.Type == nil {
+			return ""
+		}
+		return transpiler.ExprString(expr.Type, "", locals)
+	case ast.UnaryExpr:
+		if expr.Operator.Type != lexer.TokenAmpersand {
+			return ""
+		}
+		if _type := transpiler.LiteralType(expr.Value, locals); _type != "" {
+			return "*" + _type
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+// LookupMethod returns the declaration of the method called by callee if it
+// is declared in the same file. The method is only found if the receiver is
+// a variable whose type is known, since a method of the same name may belong
+// to a type of another package.
+func (transpiler *Transpiler) LookupMethod(callee ast.AccessExpr, locals *Scope) (ast.FuncDeclStmt, bool) {
+	symbolExpr, isSymbolExpr := callee.Instance.(ast.SymbolExpr)
+	if !isSymbolExpr {
+		return ast.FuncDeclStmt{}, false
+	}
+	_type, _ := locals.Lookup(symbolExpr.Symbol.Value)
+	if _type == "" {
+		return ast.FuncDeclStmt{}, false
+	}
+	typeName, _, _ := strings.Cut(strings.TrimPrefix(_type, "*"), "[")
+	method, exists := transpiler.Methods[typeName+"."+callee.Field.(ast.SymbolExpr).Symbol.Value]
+	return method, exists
+}
+
+// ResultCount returns the number of results of a checked expression without
+// its trailing error or ok. The number of results of a call is only known if
+// the function or method is declared in the same file or is a function
+// literal.
+func (transpiler *Transpiler) ResultCount(expr ast.Expr, locals *Scope) (int, bool) {
+	funcCallExpr, isFuncCallExpr := expr.(ast.FuncCallExpr)
+	if !isFuncCallExpr {
+		return 1, true
+	}
+	callee := funcCallExpr.Func
+	if indexExpr, isIndexExpr := callee.(ast.IndexExpr); isIndexExpr {
+		// an instantiation of a generic function
+		callee = indexExpr.Value
+	}
+	name := ""
+	returnTypes := make([]ast.TypeExpr, 0)
+	switch calleeExpr := callee.(type) {
+	case ast.SymbolExpr:
+		funcDeclStmt, exists := transpiler.Funcs[calleeExpr.Symbol.Value]
+		if !exists {
+			return 0, false
+		}
+		name = funcDeclStmt.Name.Value
+		returnTypes = ParameterTypes(funcDeclStmt.Results)
+	case ast.AccessExpr:
+		method, exists := transpiler.LookupMethod(calleeExpr, locals)
+		if !exists {
+			return 0, false
+		}
+		name = ReceiverTypeName(method.Receiver.Type) + "." + method.Name.Value
+		returnTypes = ParameterTypes(method.Results)
+	case ast.FuncLitExpr:
+		name = "function literal"
+		returnTypes = ParameterTypes(calleeExpr.Type.Results)
+	default:
+		return 0, false
+	}
+	if !ReturnsError(returnTypes) {
+		panic(fmt.Sprintf("\n%s... <--- %s does not return an error", transpiler.String(), name))
+	}
+	return len(returnTypes) - 1, true
+}
+
+// CheckResultCount panics if the number of targets does not match the
+// number of results of the checked expression.
+func (transpiler *Transpiler) CheckResultCount(targets []string, expr ast.Expr, locals *Scope) {
+	resultCount, known := transpiler.ResultCount(expr, locals)
+	if known && resultCount != len(targets) {
+		panic(fmt.Sprintf("\n%s... <--- assignment mismatch: %d variables but %d values", transpiler.String(), len(targets), resultCount))
+	}
+}
+
+// HoistOperands hoists the checked calls nested in operands, which Go
+// evaluates from left to right. An operand that calls a function or receives
+// from a channel before an operand with checks is hoisted into a temporary
+// as well, so that it still runs first.
+func (transpiler *Transpiler) HoistOperands(operands []ast.Expr, indent string, locals *Scope) []ast.Expr {
+	lastChecked := -1
+	for i, operand := range operands {
+		if ContainsChecked(operand) {
+			lastChecked = i
+		}
+	}
+	hoisted := make([]ast.Expr, 0)
+	for i, operand := range operands {
+		operand = transpiler.HoistCheckedCalls(operand, indent, locals)
+		if i < lastChecked && ContainsCall(operand) {
+			operand = transpiler.HoistValue(operand, indent, locals)
+		}
+		hoisted = append(hoisted, operand)
+	}
+	return hoisted
+}
+
+// HoistValue emits the assignment of expr to a new temporary, which it
+// returns, so that expr is evaluated at this point.
+func (transpiler *Transpiler) HoistValue(expr ast.Expr, indent string, locals *Scope) ast.Expr {
+	transpiler.Temps += 1
+	temp := transpiler.TempName(locals, fmt.Sprintf("tmp%d", transpiler.Temps), "Tmp")
+	transpiler.Writef("%s%s := ", indent, temp)
+	transpiler.TranspileExpr(expr, indent, locals)
+	transpiler.Write("\n")
+	locals.Declare(temp, "")
+	return ast.SymbolExpr{
+		Symbol: lexer.NewToken(lexer.TokenIdentifier, temp, 0, 0),
+	}
+}
+
+// NewListExpr returns the list of values, or nil if there are none.
+func NewListExpr(values []ast.Expr) ast.Expr {
+	if len(values) == 0 {
+		return nil
+	}
+	list := values[0]
+	for _, value := range values[1:] {
+		list = ast.ListExpr{
+			Value: list,
+			Next:  value,
+		}
+	}
+	return list
+}
+
+// HoistCheckedCalls emits every checked call and comma-ok expression nested
+// in expr, in evaluation order, as a separate statement that assigns its
+// result to a temporary. It returns expr with the checked expressions
+// replaced by their temporaries.
+func (transpiler *Transpiler) HoistCheckedCalls(exprInterface ast.Expr, indent string, locals *Scope) ast.Expr {
+	if _, token, _, isCommaOk := CommaOk(exprInterface); isCommaOk {
+		transpiler.Temps += 1
+		temp := transpiler.TempName(locals, fmt.Sprintf("tmp%d", transpiler.Temps), "Tmp")
+		transpiler.TranspileCommaOk([]string{temp}, true, exprInterface, indent, locals)
+		return ast.SymbolExpr{
+			Symbol: lexer.NewToken(lexer.TokenIdentifier, temp, token.Line, token.Column),
+		}
+	}
+	switch expr := exprInterface.(type) {
+	case ast.FuncCallExpr:
+		if _, isCheckedCall := IsCheckedCall(expr); !isCheckedCall {
+			return transpiler.HoistCheckedCallArgs(expr, indent, locals)
+		}
+		transpiler.CheckResultCount([]string{"_"}, expr, locals)
+		hoisted := transpiler.HoistCheckedCallArgs(expr, indent, locals)
+		transpiler.Temps += 1
+		temp := transpiler.TempName(locals, fmt.Sprintf("tmp%d", transpiler.Temps), "Tmp")
+		transpiler.TranspileHoistedCheckedCall([]string{temp}, true, hoisted, expr, indent, locals)
+		return ast.SymbolExpr{
+			Symbol: lexer.NewToken(lexer.TokenIdentifier, temp, expr.ParenOpen.Line, expr.ParenOpen.Column),
+		}
+	case ast.BinaryExpr:
+		if IsShortCircuit(expr) && ContainsChecked(expr.Right) {
+			expr.Left = transpiler.HoistCheckedCalls(expr.Left, indent, locals)
+			return transpiler.HoistShortCircuit(expr, indent, locals)
+		}
+		operands := transpiler.HoistOperands([]ast.Expr{expr.Left, expr.Right}, indent, locals)
+		expr.Left, expr.Right = operands[0], operands[1]
+		return expr
+	case ast.UnaryExpr:
+		expr.Value = transpiler.HoistCheckedCalls(expr.Value, indent, locals)
+		return expr
+	case ast.AccessExpr:
+		expr.Instance = transpiler.HoistCheckedCalls(expr.Instance, indent, locals)
+		return expr
+	case ast.IndexExpr:
+		operands := transpiler.HoistOperands([]ast.Expr{expr.Value, expr.Index}, indent, locals)
+		expr.Value, expr.Index = operands[0], operands[1]
+		return expr
+	case ast.SliceExpr:
+		operands := transpiler.HoistOperands([]ast.Expr{expr.Value, expr.Low, expr.High, expr.Max}, indent, locals)
+		expr.Value, expr.Low, expr.High, expr.Max = operands[0], operands[1], operands[2], operands[3]
+		return expr
+	case ast.TypeAssertExpr:
+		expr.Value = transpiler.HoistCheckedCalls(expr.Value, indent, locals)
+		return expr
+	case ast.ReceiveExpr:
+		expr.Channel = transpiler.HoistCheckedCalls(expr.Channel, indent, locals)
+		return expr
+	case ast.SendExpr:
+		operands := transpiler.HoistOperands([]ast.Expr{expr.Channel, expr.Value}, indent, locals)
+		expr.Channel, expr.Value = operands[0], operands[1]
+		return expr
+	case ast.ListExpr:
+		return NewListExpr(transpiler.HoistOperands(ListValues(expr), indent, locals))
+	case ast.CompositeLitExpr:
+		operands := make([]ast.Expr, 0)
+		for _, element := range expr.Elements {
+			operands = append(operands, element.Key, element.Value)
+		}
+		operands = transpiler.HoistOperands(operands, indent, locals)
+		elements := make([]ast.CompositeElement, 0)
+		for i, element := range expr.Elements {
+			element.Key = operands[2*i]
+			element.Value = operands[2*i+1]
+			elements = append(elements, element)
+		}
+		expr.Elements = elements
+		return expr
+	case ast.AssignmentExpr:
+		expr.Left = transpiler.HoistCheckedCalls(expr.Left, indent, locals)
+		expr.Right = transpiler.HoistCheckedCalls(expr.Right, indent, locals)
+		return expr
+	case ast.DeclAssignExpr:
+		expr.Right = transpiler.HoistCheckedCalls(expr.Right, indent, locals)
+		return expr
+	default:
+		return expr
+	}
+}
+
+// IsShortCircuit reports whether expr is a && or || expression, whose right
+// operand is only evaluated if the left operand does not decide the result.
+func IsShortCircuit(expr ast.BinaryExpr) bool {
+	return expr.Operator.Type == lexer.TokenAnd || expr.Operator.Type == lexer.TokenOr
+}
+
+// HoistShortCircuit emits a && or || expression whose right operand contains
+// checks as statements that assign its result to a temporary, which it
+// returns. The checks of the right operand only run if the left operand,
+// whose checks have already been hoisted, does not decide the result.
+func (transpiler *Transpiler) HoistShortCircuit(expr ast.BinaryExpr, indent string, locals *Scope) ast.Expr {
+	transpiler.Temps += 1
+	temp := transpiler.TempName(locals, fmt.Sprintf("tmp%d", transpiler.Temps), "Tmp")
+	transpiler.Writef("%s%s := ", indent, temp)
+	transpiler.TranspileExpr(expr.Left, indent, locals)
+	transpiler.Write("\n")
+	locals.Declare(temp, "")
+	condition := temp
+	if expr.Operator.Type == lexer.TokenOr {
+		condition = "!" + temp
+	}
+	transpiler.Writef("%sif %s {\n", indent, condition)
+	rightIndent := indent + "\t"
+	rightLocals := NewScope(locals)
+	if IsChecked(expr.Right) {
+		transpiler.CheckResultCount([]string{temp}, expr.Right, locals)
+		transpiler.TranspileChecked([]string{temp}, false, expr.Right, rightIndent, rightLocals)
+	} else {
+		right := transpiler.HoistCheckedCalls(expr.Right, rightIndent, rightLocals)
+		transpiler.Writef("%s%s = ", rightIndent, temp)
+		transpiler.TranspileExpr(right, rightIndent, rightLocals)
 		transpiler.Write("\n")
-		for _, packageName := range stmt.PackageNames {
-			transpiler.Writef("%s%s\"%s\"\n", indent, indent, packageName.Value)
+	}
+	transpiler.Writef("%s}\n", indent)
+	return ast.SymbolExpr{
+		Symbol: lexer.NewToken(lexer.TokenIdentifier, temp, expr.Operator.Line, expr.Operator.Column),
+	}
+}
+
+func (transpiler *Transpiler) TranspileReturnStmt(stmt ast.ReturnStmt, indent string, locals *Scope) {
+	if !IsChecked(stmt.Values) {
+		values := transpiler.HoistCheckedCalls(stmt.Values, indent, locals)
+		transpiler.Writef("%sreturn ", indent)
+		transpiler.TranspileExpr(values, indent, locals)
+		transpiler.Write("\n")
+		return
+	}
+	numReturnTypes := len(transpiler.ReturnTypes)
+	returnsError := ReturnsError(transpiler.ReturnTypes)
+	resultCount, known := transpiler.ResultCount(stmt.Values, locals)
+	if !known {
+		// assume the call returns what is returned by the enclosing function
+		resultCount = numReturnTypes
+		if returnsError {
+			resultCount -= 1
+		}
+	}
+	targets := make([]string, 0)
+	if resultCount == 1 {
+		targets = append(targets, transpiler.TempName(locals, "ret", "Ret"))
+	} else {
+		for i := 1; i <= resultCount; i++ {
+			targets = append(targets, transpiler.TempName(locals, fmt.Sprintf("ret%d", i), "Ret"))
+		}
+	}
+	transpiler.TranspileChecked(targets, true, stmt.Values, indent, locals)
+	values := targets
+	if returnsError && resultCount == numReturnTypes-1 {
+		// the error has already been checked
+		values = append(values, "nil")
+	}
+	transpiler.Writef("%sreturn %s\n", indent, strings.Join(values, ", "))
+}
+
+func (transpiler *Transpiler) TranspileExprStmt(stmt ast.ExprStmt, indent string, locals *Scope) {
+	switch expr := stmt.Expr.(type) {
+	case ast.DeclAssignExpr:
+		if IsChecked(expr.Right) {
+			targets := transpiler.ExprStrings(ListValues(expr.Left), indent, locals)
+			transpiler.CheckResultCount(targets, expr.Right, locals)
+			transpiler.TranspileChecked(targets, true, expr.Right, indent, locals)
+			return
+		}
+	case ast.AssignmentExpr:
+		// the result of a checked call cannot be combined with the target
+		// of an assignment operation, so it is hoisted like a nested call
+		if IsChecked(expr.Right) && expr.Operator.Type == lexer.TokenAssign {
+			left := transpiler.HoistCheckedCalls(expr.Left, indent, locals)
+			targets := transpiler.ExprStrings(ListValues(left), indent, locals)
+			transpiler.CheckResultCount(targets, expr.Right, locals)
+			transpiler.TranspileChecked(targets, false, expr.Right, indent, locals)
+			return
+		}
+	default:
+		if IsChecked(expr) {
+			resultCount, known := transpiler.ResultCount(expr, locals)
+			if !known {
+				// assume the call only returns an error
+				resultCount = 0
+			}
+			targets := make([]string, resultCount)
+			for i := range targets {
+				targets[i] = "_"
+			}
+			transpiler.TranspileChecked(targets, false, expr, indent, locals)
+			return
 		}
-		transpiler.Writef("\n%s)\n\n", indent)
 	}
+	expr := transpiler.HoistCheckedCalls(stmt.Expr, indent, locals)
+	transpiler.Write(indent)
+	transpiler.TranspileExpr(expr, indent, locals)
+	transpiler.Write("\n")
+}
+
+// TranspileBlockStmt emits a block with a scope of its own, starting at the
+// current position of the output.
+func (transpiler *Transpiler) TranspileBlockStmt(stmt ast.BlockStmt, depth int, locals *Scope) {
+	transpiler.Write("{\n")
+	transpiler.TranspileWithDepth(stmt.Body, depth+1, NewScope(locals))
+	transpiler.Writef("%s}", strings.Repeat("\t", depth))
+}
+
+// TranspileInitBlock emits the init statement of an if, for or switch
+// statement whose checks cannot be emitted in its header. The init statement
+// is moved into a block that keeps its scope, and transpile emits the rest
+// of the statement in the block.
+func (transpiler *Transpiler) TranspileInitBlock(init ast.Stmt, indent string, depth int, locals *Scope, transpile func(indent string, depth int, locals *Scope)) {
+	initLocals := NewScope(locals)
+	transpiler.Writef("%s{\n", indent)
+	ReserveNames([]ast.Stmt{init}, initLocals)
+	transpiler.TranspileWithDepth([]ast.Stmt{init}, depth+1, initLocals)
+	transpile(indent+"\t", depth+1, initLocals)
+	transpiler.Writef("%s}\n", indent)
 }
 
-func (transpiler *Transpiler) TranspileFuncDeclStmt(stmt ast.FuncDeclStmt, indent string, depth int, locals map[string]bool) {
-	locals[stmt.Name.Value] = true
-	innerLocals := make(map[string]bool)
-	for k, v := range locals {
-		innerLocals[k] = v
+func (transpiler *Transpiler) TranspileIfStmt(stmt ast.IfStmt, indent string, depth int, locals *Scope) {
+	if stmt.Init != nil && (ContainsCheckedStmt(stmt.Init) || ContainsChecked(stmt.Cond)) {
+		init := stmt.Init
+		stmt.Init = nil
+		transpiler.TranspileInitBlock(init, indent, depth, locals, func(indent string, depth int, locals *Scope) {
+			transpiler.TranspileIfStmt(stmt, indent, depth, locals)
+		})
+		return
 	}
-	transpiler.Writef("%sfunc %s", indent, stmt.Name.Value)
-	paramNameAndType := make([]string, 0)
-	for _, param := range stmt.Parameters {
-		innerLocals[param.Name.Value] = true
-		paramNameAndType = append(paramNameAndType, fmt.Sprintf("%s %s", param.Name.Value, param.Type.Value))
+	stmt.Cond = transpiler.HoistCheckedCalls(stmt.Cond, indent, locals)
+	transpiler.Write(indent)
+	transpiler.TranspileIf(stmt, indent, depth, locals)
+	transpiler.Write("\n")
+}
+
+// TranspileIf emits an if statement, without checks in its header, and its
+// else chain starting at the current position of the output.
+func (transpiler *Transpiler) TranspileIf(stmt ast.IfStmt, indent string, depth int, locals *Scope) {
+	initLocals := NewScope(locals)
+	transpiler.Write("if ")
+	transpiler.InHeader = true
+	if stmt.Init != nil {
+		transpiler.TranspileExpr(stmt.Init.(ast.ExprStmt).Expr, indent, initLocals)
+		transpiler.Write("; ")
 	}
-	transpiler.Writef("(%s) ", strings.Join(paramNameAndType, ", "))
-	if len(stmt.ReturnTypes) > 0 {
-		returnTypes := make([]string, 0)
-		for _, _type := range stmt.ReturnTypes {
-			returnTypes = append(returnTypes, _type.Value)
+	transpiler.TranspileExpr(stmt.Cond, indent, initLocals)
+	transpiler.InHeader = false
+	transpiler.Write(" ")
+	transpiler.TranspileBlockStmt(stmt.Block.(ast.BlockStmt), depth, initLocals)
+	switch elseStmt := stmt.Else.(type) {
+	case ast.BlockStmt:
+		transpiler.Write(" else ")
+		transpiler.TranspileBlockStmt(elseStmt, depth, initLocals)
+	case ast.IfStmt:
+		hasChecks := ContainsChecked(elseStmt.Cond)
+		if elseStmt.Init != nil {
+			hasChecks = hasChecks || ContainsChecked(elseStmt.Init.(ast.ExprStmt).Expr)
 		}
-		if len(returnTypes) > 1 {
-			transpiler.Writef("(%s) ", strings.Join(returnTypes, ", "))
+		if hasChecks {
+			// the checks have to be emitted before the if, which is only
+			// possible in an else block
+			transpiler.Write(" else {\n")
+			elseLocals := NewScope(initLocals)
+			transpiler.TranspileIfStmt(elseStmt, indent+"\t", depth+1, elseLocals)
+			transpiler.Writef("%s}", indent)
 		} else {
-			transpiler.Writef("%s ", strings.Join(returnTypes, ", "))
+			transpiler.Write(" else ")
+			transpiler.TranspileIf(elseStmt, indent, depth, initLocals)
 		}
 	}
+}
+
+// ContainsCheckedStmt reports whether a simple statement, such as the init
+// statement of a for loop, contains a checked call or a comma-ok expression.
+func ContainsCheckedStmt(stmtInterface ast.Stmt) bool {
+	switch stmt := stmtInterface.(type) {
+	case ast.ExprStmt:
+		return ContainsChecked(stmt.Expr)
+	case ast.IncDecStmt:
+		return ContainsChecked(stmt.Value)
+	default:
+		return false
+	}
+}
+
+// RedeclareStmt returns a statement that declares the variables declared by
+// stmt again with their current values, such as `i := i`, or nil if stmt
+// declares none.
+func RedeclareStmt(stmt ast.Stmt) ast.Stmt {
+	exprStmt, isExprStmt := stmt.(ast.ExprStmt)
+	if !isExprStmt {
+		return nil
+	}
+	declAssignExpr, isDeclAssignExpr := exprStmt.Expr.(ast.DeclAssignExpr)
+	if !isDeclAssignExpr {
+		return nil
+	}
+	names := make([]ast.Expr, 0)
+	for _, target := range ListValues(declAssignExpr.Left) {
+		if symbolExpr, isSymbolExpr := target.(ast.SymbolExpr); isSymbolExpr && symbolExpr.Symbol.Value != "_" {
+			names = append(names, symbolExpr)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return ast.ExprStmt{
+		Expr: ast.DeclAssignExpr{
+			Left:  NewListExpr(names),
+			Right: NewListExpr(names),
+		},
+	}
+}
+
+// TranspileSimpleStmt emits a simple statement without checks, such as the
+// post statement of a for loop, starting at the current position of the
+// output.
+func (transpiler *Transpiler) TranspileSimpleStmt(stmtInterface ast.Stmt, indent string, locals *Scope) {
+	switch stmt := stmtInterface.(type) {
+	case ast.ExprStmt:
+		transpiler.TranspileExpr(stmt.Expr, indent, locals)
+	case ast.IncDecStmt:
+		transpiler.TranspileExpr(stmt.Value, indent, locals)
+		switch stmt.Operator.Type {
+		case lexer.TokenIncrement:
+			transpiler.Write("++")
+		case lexer.TokenDecrement:
+			transpiler.Write("--")
+		}
+	}
+}
+
+// TranspileDeferredCall emits the call of a defer or go statement. Checks
+// nested in the call run when the statement runs, like the evaluation of the
+// function value and the arguments. A checked call is wrapped in a function
+// literal, so that its error is checked when the call runs, and its
+// function value and arguments are evaluated into temporaries before. There
+// is no result to return an error from in the literal.
+func (transpiler *Transpiler) TranspileDeferredCall(keyword string, call ast.Expr, indent string, locals *Scope) {
+	funcCallExpr, isCheckedCall := IsCheckedCall(call)
+	if !isCheckedCall {
+		call = transpiler.HoistCheckedCalls(call, indent, locals)
+		transpiler.Writef("%s%s ", indent, keyword)
+		transpiler.TranspileExpr(call, indent, locals)
+		transpiler.Write("\n")
+		return
+	}
+	hoisted := transpiler.HoistDeferredOperands(funcCallExpr, indent, locals)
+	resultCount, known := transpiler.ResultCount(funcCallExpr, locals)
+	if !known {
+		// assume the call only returns an error
+		resultCount = 0
+	}
+	targets := make([]string, resultCount)
+	for i := range targets {
+		targets[i] = "_"
+	}
+	transpiler.Writef("%s%s func() {\n", indent, keyword)
+	outerReturnTypes := transpiler.ReturnTypes
+	transpiler.ReturnTypes = make([]ast.TypeExpr, 0)
+	transpiler.TranspileHoistedCheckedCall(targets, false, hoisted, funcCallExpr, indent+"\t", NewFuncScope(locals))
+	transpiler.ReturnTypes = outerReturnTypes
+	transpiler.Writef("%s}()\n", indent)
+}
+
+// HoistDeferredOperands evaluates the function value and the arguments of a
+// deferred checked call into temporaries and returns the call with its
+// operands replaced by them. Functions that cannot change, such as a
+// function declared in the file, and constants are left in place.
+func (transpiler *Transpiler) HoistDeferredOperands(expr ast.FuncCallExpr, indent string, locals *Scope) ast.FuncCallExpr {
+	operands := append([]ast.Expr{expr.Func}, ListValues(expr.Args)...)
+	hoisted := make([]ast.Expr, 0)
+	for i, operand := range operands {
+		isChecked := IsChecked(operand)
+		operand = transpiler.HoistCheckedCalls(operand, indent, locals)
+		switch {
+		case isChecked:
+			// the result of a checked call is already in a temporary
+		case i == 0 && transpiler.IsFixedFunc(operand, locals):
+		case i > 0 && IsConstant(operand, locals):
+		default:
+			operand = transpiler.HoistValue(operand, indent, locals)
+		}
+		hoisted = append(hoisted, operand)
+	}
+	expr.Func = hoisted[0]
+	expr.Args = NewListExpr(hoisted[1:])
+	return expr
+}
+
+// IsFixedFunc reports whether the function value expr always refers to the
+// same function, like a function declared in the file or a function literal.
+func (transpiler *Transpiler) IsFixedFunc(exprInterface ast.Expr, locals *Scope) bool {
+	switch expr := exprInterface.(type) {
+	case ast.SymbolExpr:
+		name := expr.Symbol.Value
+		_, isFunc := transpiler.Funcs[name]
+		return isFunc && locals.IsPackageLevel(name)
+	case ast.IndexExpr:
+		// an instantiation of a generic function
+		return transpiler.IsFixedFunc(expr.Value, locals)
+	case ast.FuncLitExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsConstant reports whether expr has the same value whenever it is
+// evaluated, like a literal, one of the predeclared nil, true, false and
+// iota or an operation on them. Any other name may be a variable declared
+// later or in another file.
+func IsConstant(exprInterface ast.Expr, locals *Scope) bool {
+	switch expr := exprInterface.(type) {
+	case ast.NumberExpr, ast.StringExpr:
+		return true
+	case ast.SymbolExpr:
+		switch expr.Symbol.Value {
+		case "nil", "true", "false", "iota":
+			return !locals.IsDeclared(expr.Symbol.Value)
+		default:
+			return false
+		}
+	case ast.UnaryExpr:
+		switch expr.Operator.Type {
+		case lexer.TokenMinus, lexer.TokenPlus, lexer.TokenNot, lexer.TokenCaret:
+			return IsConstant(expr.Value, locals)
+		default:
+			return false
+		}
+	case ast.BinaryExpr:
+		return IsConstant(expr.Left, locals) && IsConstant(expr.Right, locals)
+	default:
+		return false
+	}
+}
+
+func (transpiler *Transpiler) TranspileIncDecStmt(stmt ast.IncDecStmt, indent string, locals *Scope) {
+	stmt.Value = transpiler.HoistCheckedCalls(stmt.Value, indent, locals)
+	transpiler.Write(indent)
+	transpiler.TranspileSimpleStmt(stmt, indent, locals)
+	transpiler.Write("\n")
+}
+
+// TranspileLabel emits the label of a loop, if any, outdented like gofmt
+// does.
+func (transpiler *Transpiler) TranspileLabel(label lexer.Token, indent string) {
+	if label.Value != "" {
+		transpiler.Writef("%s%s:\n", strings.TrimPrefix(indent, "\t"), label.Value)
+	}
+}
+
+func (transpiler *Transpiler) TranspileForStmt(stmt ast.ForStmt, indent string, depth int, locals *Scope) {
+	if stmt.Init != nil && ContainsCheckedStmt(stmt.Init) {
+		// the variables of the init statement are declared again in the
+		// header, so that every iteration still has its own copy
+		init := stmt.Init
+		stmt.Init = RedeclareStmt(init)
+		transpiler.TranspileInitBlock(init, indent, depth, locals, func(indent string, depth int, locals *Scope) {
+			transpiler.TranspileForStmt(stmt, indent, depth, locals)
+		})
+		return
+	}
+	if stmt.Post != nil && ContainsCheckedStmt(stmt.Post) {
+		panic(fmt.Sprintf("\n%s... <--- checks are not supported in the post statement of a for loop at line %d", transpiler.String(), stmt.For.Line))
+	}
+	forLocals := NewScope(locals)
+	transpiler.TranspileLabel(stmt.Label, indent)
+	transpiler.Writef("%sfor ", indent)
+	transpiler.InHeader = true
+	if stmt.Init != nil || stmt.Post != nil {
+		transpiler.TranspileSimpleStmt(stmt.Init, indent, forLocals)
+		transpiler.Write("; ")
+		if !ContainsChecked(stmt.Cond) {
+			transpiler.TranspileExpr(stmt.Cond, indent, forLocals)
+		}
+		transpiler.Write("; ")
+		transpiler.TranspileSimpleStmt(stmt.Post, indent, forLocals)
+		transpiler.Write(" ")
+	} else if stmt.Cond != nil && !ContainsChecked(stmt.Cond) {
+		transpiler.TranspileExpr(stmt.Cond, indent, forLocals)
+		transpiler.Write(" ")
+	}
+	transpiler.InHeader = false
+	if !ContainsChecked(stmt.Cond) {
+		transpiler.TranspileBlockStmt(stmt.Block.(ast.BlockStmt), depth, forLocals)
+		transpiler.Write("\n")
+		return
+	}
+	// the condition is evaluated with its checks at the start of every
+	// iteration
+	body := stmt.Block.(ast.BlockStmt).Body
+	bodyLocals := NewScope(forLocals)
+	ReserveNames(body, bodyLocals)
 	transpiler.Write("{\n")
-	transpiler.TranspileWithDepth(stmt.Block.(ast.BlockStmt).Body, depth+1, innerLocals)
-	transpiler.Write("}\n\n")
+	cond := transpiler.HoistCheckedCalls(stmt.Cond, indent+"\t", bodyLocals)
+	transpiler.Writef("%s\tif !(", indent)
+	transpiler.TranspileExpr(cond, indent+"\t", bodyLocals)
+	transpiler.Writef(") {\n%s\t\tbreak\n%s\t}\n", indent, indent)
+	transpiler.TranspileWithDepth(body, depth+1, bodyLocals)
+	transpiler.Writef("%s}\n", indent)
 }
 
-func (transpiler *Transpiler) TranspileReturnStmt(stmt ast.ReturnStmt, indent string, locals map[string]bool) {
-	funcCallExpr, isFuncCallExpr := stmt.Values.(ast.FuncCallExpr)
-	if isFuncCallExpr && funcCallExpr.OrPanic {
-		// defer the return keyword
-	} else {
-		transpiler.Writef("%sreturn ", indent)
+func (transpiler *Transpiler) TranspileRangeStmt(stmt ast.RangeStmt, indent string, depth int, locals *Scope) {
+	rangeExpr := transpiler.HoistCheckedCalls(stmt.Range, indent, locals)
+	forLocals := NewScope(locals)
+	transpiler.TranspileLabel(stmt.Label, indent)
+	transpiler.Writef("%sfor ", indent)
+	if stmt.Key != nil {
+		transpiler.TranspileExpr(stmt.Key, indent, forLocals)
+		if stmt.Value != nil {
+			transpiler.Write(", ")
+			transpiler.TranspileExpr(stmt.Value, indent, forLocals)
+		}
+		if stmt.Declare {
+			for _, value := range []ast.Expr{stmt.Key, stmt.Value} {
+				if symbolExpr, isSymbolExpr := value.(ast.SymbolExpr); isSymbolExpr {
+					forLocals.Declare(symbolExpr.Symbol.Value, "")
+				}
+			}
+			transpiler.Write(" := ")
+		} else {
+			transpiler.Write(" = ")
+		}
+	}
+	transpiler.Write("range ")
+	transpiler.InHeader = true
+	transpiler.TranspileExpr(rangeExpr, indent, locals)
+	transpiler.InHeader = false
+	transpiler.Write(" ")
+	transpiler.TranspileBlockStmt(stmt.Block.(ast.BlockStmt), depth, forLocals)
+	transpiler.Write("\n")
+}
+
+func (transpiler *Transpiler) TranspileSwitchStmt(stmt ast.SwitchStmt, indent string, depth int, locals *Scope) {
+	for _, caseClause := range stmt.Cases {
+		if ContainsChecked(caseClause.Values) {
+			panic(fmt.Sprintf("\n%s... <--- checks are not supported in case expressions at line %d", transpiler.String(), caseClause.Case.Line))
+		}
+	}
+	if stmt.Init != nil && (ContainsCheckedStmt(stmt.Init) || ContainsChecked(stmt.Tag)) {
+		init := stmt.Init
+		stmt.Init = nil
+		transpiler.TranspileInitBlock(init, indent, depth, locals, func(indent string, depth int, locals *Scope) {
+			transpiler.TranspileSwitchStmt(stmt, indent, depth, locals)
+		})
+		return
+	}
+	tag := transpiler.HoistCheckedCalls(stmt.Tag, indent, locals)
+	switchLocals := NewScope(locals)
+	transpiler.TranspileLabel(stmt.Label, indent)
+	transpiler.Writef("%sswitch ", indent)
+	transpiler.InHeader = true
+	if stmt.Init != nil {
+		transpiler.TranspileExpr(stmt.Init.(ast.ExprStmt).Expr, indent, switchLocals)
+		transpiler.Write("; ")
+	}
+	if tag != nil {
+		transpiler.TranspileExpr(tag, indent, switchLocals)
+		transpiler.Write(" ")
+	}
+	transpiler.InHeader = false
+	transpiler.Write("{\n")
+	for _, caseClause := range stmt.Cases {
+		if caseClause.Values == nil {
+			transpiler.Writef("%sdefault:\n", indent)
+		} else {
+			transpiler.Writef("%scase ", indent)
+			transpiler.TranspileExpr(caseClause.Values, indent, switchLocals)
+			transpiler.Write(":\n")
+		}
+		transpiler.TranspileWithDepth(caseClause.Body, depth+1, NewScope(switchLocals))
+	}
+	transpiler.Writef("%s}\n", indent)
+}
+
+func (transpiler *Transpiler) TranspileTypeSwitchStmt(stmt ast.TypeSwitchStmt, indent string, depth int, locals *Scope) {
+	if stmt.Init != nil && (ContainsCheckedStmt(stmt.Init) || ContainsChecked(stmt.Value)) {
+		init := stmt.Init
+		stmt.Init = nil
+		transpiler.TranspileInitBlock(init, indent, depth, locals, func(indent string, depth int, locals *Scope) {
+			transpiler.TranspileTypeSwitchStmt(stmt, indent, depth, locals)
+		})
+		return
+	}
+	value := transpiler.HoistCheckedCalls(stmt.Value, indent, locals)
+	switchLocals := NewScope(locals)
+	transpiler.TranspileLabel(stmt.Label, indent)
+	transpiler.Writef("%sswitch ", indent)
+	transpiler.InHeader = true
+	if stmt.Init != nil {
+		transpiler.TranspileExpr(stmt.Init.(ast.ExprStmt).Expr, indent, switchLocals)
+		transpiler.Write("; ")
+	}
+	if stmt.Binding.Value != "" {
+		transpiler.Writef("%s := ", stmt.Binding.Value)
+	}
+	transpiler.TranspileExpr(value, indent, switchLocals)
+	transpiler.InHeader = false
+	transpiler.Write(".(type) {\n")
+	for _, caseClause := range stmt.Cases {
+		caseLocals := NewScope(switchLocals)
+		types := ListValues(caseClause.Values)
+		if caseClause.Values == nil {
+			transpiler.Writef("%sdefault:\n", indent)
+		} else {
+			transpiler.Writef("%scase ", indent)
+			transpiler.TranspileExpr(caseClause.Values, indent, switchLocals)
+			transpiler.Write(":\n")
+		}
+		// the binding has the type of the case if it lists exactly one type,
+		// otherwise the type of the value
+		bindingType := ""
+		if len(types) == 1 {
+			bindingType = transpiler.ExprString(types[0], indent, switchLocals)
+			if bindingType == "nil" {
+				bindingType = ""
+			}
+		}
+		caseLocals.Declare(stmt.Binding.Value, bindingType)
+		transpiler.TranspileWithDepth(caseClause.Body, depth+1, caseLocals)
+	}
+	transpiler.Writef("%s}\n", indent)
+}
+
+// TranspileSelectStmt emits a select statement. The variables declared by
+// the receive of a case are in the scope of the case.
+func (transpiler *Transpiler) TranspileSelectStmt(stmt ast.SelectStmt, indent string, depth int, locals *Scope) {
+	for _, caseClause := range stmt.Cases {
+		if ContainsChecked(caseClause.Values) {
+			panic(fmt.Sprintf("\n%s... <--- checks are not supported in select cases at line %d", transpiler.String(), caseClause.Case.Line))
+		}
+	}
+	transpiler.TranspileLabel(stmt.Label, indent)
+	transpiler.Writef("%sselect {\n", indent)
+	for _, caseClause := range stmt.Cases {
+		caseLocals := NewScope(locals)
+		if caseClause.Values == nil {
+			transpiler.Writef("%sdefault:\n", indent)
+		} else {
+			transpiler.Writef("%scase ", indent)
+			transpiler.TranspileExpr(caseClause.Values, indent, caseLocals)
+			transpiler.Write(":\n")
+		}
+		transpiler.TranspileWithDepth(caseClause.Body, depth+1, caseLocals)
+	}
+	transpiler.Writef("%s}\n", indent)
+}
+
+func (transpiler *Transpiler) TranspileBranchStmt(stmt ast.BranchStmt, indent string) {
+	transpiler.Write(indent)
+	switch stmt.Keyword.Type {
+	case lexer.TokenBreak:
+		transpiler.Write("break")
+	case lexer.TokenContinue:
+		transpiler.Write("continue")
+	case lexer.TokenFallthrough:
+		transpiler.Write("fallthrough")
+	}
+	if stmt.Label.Value != "" {
+		transpiler.Writef(" %s", stmt.Label.Value)
 	}
-	transpiler.TranspileExpr(stmt, stmt.Values, indent, locals)
 	transpiler.Write("\n")
 }
 
-func (transpiler *Transpiler) TranspileVarDeclStmt(stmt ast.VarDeclStmt, indent string, locals map[string]bool) {
-	locals[stmt.Name.Value] = true
-	transpiler.Writef("%svar %s %s\n", indent, stmt.Name.Value, stmt.Type.Value)
+func (transpiler *Transpiler) TranspileTypeDeclStmt(stmt ast.TypeDeclStmt, indent string, depth int, locals *Scope) {
+	locals.Declare(stmt.Name.Value, "")
+	transpiler.Writef("%stype %s", indent, stmt.Name.Value)
+	transpiler.TranspileTypeParams(stmt.TypeParams, indent, NewScope(locals))
+	transpiler.Write(" ")
+	transpiler.TranspileTypeExpr(stmt.Type, indent, locals)
+	if depth == 0 {
+		transpiler.Write("\n\n")
+	} else {
+		transpiler.Write("\n")
+	}
+}
+
+func (transpiler *Transpiler) TranspileVarDeclStmt(stmt ast.VarDeclStmt, indent string, locals *Scope) {
+	_type := transpiler.ExprString(stmt.Type, indent, locals)
+	locals.Declare(stmt.Name.Value, _type)
+	transpiler.Writef("%svar %s %s\n", indent, stmt.Name.Value, _type)
 }
 
-func (transpiler *Transpiler) TranspileWithDepth(_ast []ast.Stmt, depth int, locals map[string]bool) {
+// ReserveNames reserves the names declared by the statements of a block in
+// the scope of the block.
+func ReserveNames(_ast []ast.Stmt, locals *Scope) {
+	for _, stmtInterface := range _ast {
+		switch stmt := stmtInterface.(type) {
+		case ast.TypeDeclStmt:
+			locals.Reserve(stmt.Name.Value)
+		case ast.VarDeclStmt:
+			locals.Reserve(stmt.Name.Value)
+		case ast.ExprStmt:
+			if declAssignExpr, isDeclAssignExpr := stmt.Expr.(ast.DeclAssignExpr); isDeclAssignExpr {
+				for _, value := range ListValues(declAssignExpr.Left) {
+					if symbolExpr, isSymbolExpr := value.(ast.SymbolExpr); isSymbolExpr {
+						locals.Reserve(symbolExpr.Symbol.Value)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (transpiler *Transpiler) TranspileWithDepth(_ast []ast.Stmt, depth int, locals *Scope) {
 	indent := strings.Repeat("\t", depth)
+	ReserveNames(_ast, locals)
 	for _, stmtInterface := range _ast {
 		switch stmt := stmtInterface.(type) {
 		case ast.PackageStmt:
 			transpiler.TranspilePackageStmt(stmt, indent)
 		case ast.ImportStmt:
-			transpiler.TranspileImportStmt(stmt, indent)
+			transpiler.TranspileImportStmt(stmt, locals)
+		case ast.DirectiveStmt:
+			if depth > 0 {
+				panic(fmt.Sprintf("directive //gox:%s at line %d must be at top level", stmt.Directive.Value, stmt.Directive.Line))
+			}
+			// applied before transpiling
 		case ast.FuncDeclStmt:
 			transpiler.TranspileFuncDeclStmt(stmt, indent, depth, locals)
 		case ast.ReturnStmt:
 			transpiler.TranspileReturnStmt(stmt, indent, locals)
+		case ast.TypeDeclStmt:
+			transpiler.TranspileTypeDeclStmt(stmt, indent, depth, locals)
 		case ast.VarDeclStmt:
 			transpiler.TranspileVarDeclStmt(stmt, indent, locals)
+		case ast.IfStmt:
+			transpiler.TranspileIfStmt(stmt, indent, depth, locals)
+		case ast.ForStmt:
+			transpiler.TranspileForStmt(stmt, indent, depth, locals)
+		case ast.RangeStmt:
+			transpiler.TranspileRangeStmt(stmt, indent, depth, locals)
+		case ast.SwitchStmt:
+			transpiler.TranspileSwitchStmt(stmt, indent, depth, locals)
+		case ast.TypeSwitchStmt:
+			transpiler.TranspileTypeSwitchStmt(stmt, indent, depth, locals)
+		case ast.SelectStmt:
+			transpiler.TranspileSelectStmt(stmt, indent, depth, locals)
+		case ast.BranchStmt:
+			transpiler.TranspileBranchStmt(stmt, indent)
+		case ast.DeferStmt:
+			transpiler.TranspileDeferredCall("defer", stmt.Call, indent, locals)
+		case ast.GoStmt:
+			transpiler.TranspileDeferredCall("go", stmt.Call, indent, locals)
+		case ast.IncDecStmt:
+			transpiler.TranspileIncDecStmt(stmt, indent, locals)
 		case ast.ExprStmt:
-			transpiler.TranspileExpr(stmt, stmt.Expr, indent, locals)
+			transpiler.TranspileExprStmt(stmt, indent, locals)
 		default:
 			panic(fmt.Sprintf("\n%s%s--- here\n%sunhandled %s", transpiler.StringBuilder.String(), indent, indent, reflect.TypeOf(stmt)))
 		}
@@ -2,7 +2,9 @@ package transpiler_test
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/tobiashort/gox/assert"
@@ -23,3 +25,205 @@ func TestTranspileExamples(t *testing.T) {
 		})
 	}
 }
+
+// TestRunExamples compiles and runs every transpiled example and compares
+// what it prints. The examples in panics are expected to end with a panic
+// with the given message.
+func TestRunExamples(t *testing.T) {
+	outputs := map[string]string{
+		"catch.gox": `no config
+0
+nothing to remove
+`,
+		"channels.gox": `4 1 42
+`,
+		"comma_ok.gox": `gox 1
+0s
+`,
+		"composite_lit.gox": `{1 2} [a b] map[a:1] [[1 2] [3 4]] [{1 2} {3 0}] map[diagonal:{{0 0} {5 5}} empty:{{0 0} {0 0}}] {gox true}
+same point
+{5 6}
+one entry
+[103 111 120] gox
+4 [2 3 5 7]
+0 [1 2]
+1 [3 4]
+`,
+		"defer_go.gox": `4 0
+cleanup failed: true
+done
+`,
+		"deferred_args.gox": `parse 7
+main done 8
+work 0
+run first 6
+work 7
+work 5
+`,
+		"evaluation_order.gox": `eval a
+parse 1
+a 1
+eval bb
+parse 2
+4
+eval c
+parse 3
+[c 3]
+eval d
+parse 4
+d 4
+`,
+		"for_loop.gox": `40
+128
+64
+0
+1
+2
+again
+again
+range
+over
+func
+0 49
+1 50
+2 51
+3 52
+1 0
+2 0
+2 1
+9
+`,
+		"func_call.gox": `Hello World!
+`,
+		"func_decl.gox": "",
+		"func_lit.gox": `true
+[a b c] 2 BANANA 12 42 7
+`,
+		"generics.gox": `answer 42
+2 4 42 4
+1
+`,
+		"greeting.gox": `G'day mate!
+`,
+		"hoist.gox": `42
+50 3
+42
+`,
+		"if_else.gox": `greater 7
+at most three
+large medium zero
+`,
+		"interface.gox": `gox 2 map[ok:true]
+`,
+		"loop_variables.gox": `0
+1
+2
+`,
+		"math_expr.gox": "",
+		"methods.gox": `counter 42 43 3
+80
+2 true 3 go gox
+`,
+		"multi_value.gox": `ab ab
+gh gh
+`,
+		"onerror.gox": "",
+		"operators.gox": `10 4 21 2 1
+3 7 4 4 56 3
+false true false false true true
+13 20 5 10 -10 -8
+22 80
+true false false
+3
+5
+3 3
+positive
+x is never parsed
+false
+`,
+		"or_else.gox": `anonymous
+unknown
+{0 guest} {0 admin} {0 }
+1 10
+`,
+		"or_panic.gox": `
+`,
+		"or_return.gox": "",
+		"or_wrap.gox":   "",
+		"scopes.gox": `1
+x
+user 1 <nil> 1 1y
+1
+1 original
+`,
+		"slices.gox": `[hello world] [2 4 4] [10 2] [4 5] [10 2 4 4 5] map[a:1 world:5] LEAF map[LEAF:0] world
+eLLo,  he
+`,
+		"struct.gox": `{"id":0,"name":"gox","emails":null,"settings":null,"Scores":[0,0,0],"address":{"Street":"","city":""},"Friends":null} {}
+`,
+		"switch.gox": `negative zero positive
+false true
+one
+two or less
+three
+0
+1
+`,
+		"type_switch.gox": `nil
+int 42
+text gox
+error failed
+pointer true
+other true true false
+`,
+		"types.gox": `a, b, cx-y 0 true [1 22 333] [a bb] 10 8080
+true
+`,
+	}
+	panics := map[string]string{
+		"or_panic.gox":  "or_panic.gox:20: foo(): foobar",
+		"or_return.gox": "or_return.gox:30: double(\"2\"): cannot parse 2",
+	}
+	entries, err := os.ReadDir(filepath.Join("..", "examples"))
+	assert.Nil(err)
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "var_decl.gox" {
+			// declares err without using it, which Go rejects
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			output, exists := outputs[name]
+			if !exists {
+				t.Fatalf("no expected output for %s", name)
+			}
+			data, err := os.ReadFile(filepath.Join("..", "examples", name))
+			assert.Nil(err)
+			transpiler := transpiler.NewTranspiler()
+			transpiler.FileName = name
+			source := transpiler.Transpile(string(data))
+			dir := t.TempDir()
+			assert.Nil(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.23\n"), 0o644))
+			assert.Nil(os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644))
+			vet := exec.Command("go", "vet", ".")
+			vet.Dir = dir
+			if vetOutput, err := vet.CombinedOutput(); err != nil {
+				t.Fatalf("%s\n%s\n%s", err, vetOutput, source)
+			}
+			var stderr strings.Builder
+			run := exec.Command("go", "run", ".")
+			run.Dir = dir
+			run.Stderr = &stderr
+			actual, err := run.Output()
+			if message, panics := panics[name]; panics {
+				if err == nil || !strings.Contains(stderr.String(), "panic: "+message) {
+					t.Fatalf("expected panic %q, got %v\n%s", message, err, stderr.String())
+				}
+			} else if err != nil {
+				t.Fatalf("%s\n%s\n%s", err, stderr.String(), source)
+			}
+			assert.Eq(string(actual), output)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package transpiler
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tobiashort/gox/ast"
+)
+
+// TranspileTypeExpr emits a type. Multi-line types, such as structs, are
+// indented to match a statement at the given indent.
+func (transpiler *Transpiler) TranspileTypeExpr(typeExpr ast.TypeExpr, indent string, locals *Scope) {
+	switch _type := typeExpr.(type) {
+	case ast.NamedType:
+		if _type.Package.Value != "" {
+			transpiler.Writef("%s.", _type.Package.Value)
+		}
+		transpiler.Write(_type.Name.Value)
+		if len(_type.TypeArgs) > 0 {
+			transpiler.Write("[")
+			for i, typeArg := range _type.TypeArgs {
+				if i > 0 {
+					transpiler.Write(", ")
+				}
+				transpiler.TranspileTypeExpr(typeArg, indent, locals)
+			}
+			transpiler.Write("]")
+		}
+	case ast.PointerType:
+		transpiler.Write("*")
+		transpiler.TranspileTypeExpr(_type.Elem, indent, locals)
+	case ast.SliceType:
+		transpiler.Write("[]")
+		transpiler.TranspileTypeExpr(_type.Elem, indent, locals)
+	case ast.ArrayType:
+		transpiler.Write("[")
+		if _type.Ellipsis {
+			transpiler.Write("...")
+		} else {
+			transpiler.TranspileExpr(_type.Len, indent, locals)
+		}
+		transpiler.Write("]")
+		transpiler.TranspileTypeExpr(_type.Elem, indent, locals)
+	case ast.MapType:
+		transpiler.Write("map[")
+		transpiler.TranspileTypeExpr(_type.Key, indent, locals)
+		transpiler.Write("]")
+		transpiler.TranspileTypeExpr(_type.Value, indent, locals)
+	case ast.ChanType:
+		switch _type.Dir {
+		case ast.ChanSend:
+			transpiler.Write("chan<- ")
+		case ast.ChanRecv:
+			transpiler.Write("<-chan ")
+		default:
+			transpiler.Write("chan ")
+		}
+		transpiler.TranspileTypeExpr(_type.Value, indent, locals)
+	case ast.FuncType:
+		transpiler.Write("func")
+		transpiler.TranspileSignature(_type.Parameters, _type.Results, indent, locals)
+	case ast.StructType:
+		transpiler.TranspileStructType(_type, indent, locals)
+	case ast.InterfaceType:
+		transpiler.TranspileInterfaceType(_type, indent, locals)
+	default:
+		panic(fmt.Sprintf("\n%s... <--- unhandled %s", transpiler.String(), reflect.TypeOf(typeExpr)))
+	}
+}
+
+// TranspileStructType emits a struct type with its fields, types and tags
+// aligned in columns the way gofmt does.
+func (transpiler *Transpiler) TranspileStructType(_type ast.StructType, indent string, locals *Scope) {
+	if len(_type.Fields) == 0 {
+		transpiler.Write("struct{}")
+		return
+	}
+	transpiler.Write("struct {\n")
+	fieldIndent := indent + "\t"
+	lines := make([]string, 0)
+	for _, field := range _type.Fields {
+		cells := make([]string, 0)
+		if len(field.Names) > 0 {
+			names := make([]string, 0)
+			for _, name := range field.Names {
+				names = append(names, name.Value)
+			}
+			cells = append(cells, strings.Join(names, ", "))
+		}
+		fieldType := transpiler.ExprString(field.Type, fieldIndent, locals)
+		tag := ""
+		if field.Tag.Type != "" {
+			tag = transpiler.ExprString(ast.StringExpr{String: field.Tag}, fieldIndent, locals)
+		}
+		firstLine, rest, isMultiLine := strings.Cut(fieldType, "\n")
+		cells = append(cells, firstLine)
+		if !isMultiLine {
+			if tag != "" {
+				cells = append(cells, tag)
+			}
+			lines = append(lines, strings.Join(cells, "\t"))
+			continue
+		}
+		// a multi-line type ends the columns, like gofmt does
+		lines = append(lines, strings.Join(cells, "\t"))
+		transpiler.WriteColumns(lines, fieldIndent)
+		lines = lines[:0]
+		transpiler.Write(rest)
+		if tag != "" {
+			transpiler.Writef(" %s", tag)
+		}
+		transpiler.Write("\n")
+	}
+	transpiler.WriteColumns(lines, fieldIndent)
+	transpiler.Writef("%s}", indent)
+}
+
+func (transpiler *Transpiler) TranspileInterfaceType(_type ast.InterfaceType, indent string, locals *Scope) {
+	if len(_type.Elems) == 0 {
+		transpiler.Write("interface{}")
+		return
+	}
+	transpiler.Write("interface {\n")
+	for _, elem := range _type.Elems {
+		transpiler.Writef("%s\t", indent)
+		if elem.Name.Value != "" {
+			transpiler.Write(elem.Name.Value)
+			transpiler.TranspileSignature(elem.Parameters, elem.Results, indent+"\t", locals)
+		} else {
+			transpiler.TranspileTypeTerms(elem.Terms, indent+"\t", locals)
+		}
+		transpiler.Write("\n")
+	}
+	transpiler.Writef("%s}", indent)
+}
+
+// TranspileTypeTerms emits a union of type terms.
+func (transpiler *Transpiler) TranspileTypeTerms(terms []ast.TypeTerm, indent string, locals *Scope) {
+	for i, term := range terms {
+		if i > 0 {
+			transpiler.Write(" | ")
+		}
+		if term.Tilde {
+			transpiler.Write("~")
+		}
+		transpiler.TranspileTypeExpr(term.Type, indent, locals)
+	}
+}
+
+// TranspileTypeParams emits the type parameters of a generic function or
+// type, if any, and declares them in locals.
+func (transpiler *Transpiler) TranspileTypeParams(typeParams []ast.TypeParam, indent string, locals *Scope) {
+	if len(typeParams) == 0 {
+		return
+	}
+	transpiler.Write("[")
+	for i, typeParam := range typeParams {
+		if i > 0 {
+			transpiler.Write(", ")
+		}
+		names := make([]string, 0)
+		for _, name := range typeParam.Names {
+			locals.Declare(name.Value, "")
+			names = append(names, name.Value)
+		}
+		transpiler.Writef("%s ", strings.Join(names, ", "))
+		transpiler.TranspileTypeTerms(typeParam.Constraint, indent, locals)
+	}
+	transpiler.Write("]")
+}
+
+// WriteColumns writes lines of tab separated cells with the cells aligned in
+// columns, like gofmt aligns struct fields and composite literal elements.
+// Each line is prefixed with indent.
+func (transpiler *Transpiler) WriteColumns(lines []string, indent string) {
+	columns := strings.Builder{}
+	writer := tabwriter.NewWriter(&columns, 0, 8, 1, ' ', 0)
+	for _, line := range lines {
+		fmt.Fprintln(writer, line)
+	}
+	writer.Flush()
+	for _, line := range strings.SplitAfter(columns.String(), "\n") {
+		if line != "" {
+			transpiler.Write(indent + line)
+		}
+	}
+}